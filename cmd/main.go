@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
+	"strings"
 	"time"
 
+	"github.com/dashboard-platform/template-service/internal/auth"
 	"github.com/dashboard-platform/template-service/internal/config"
 	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/internal/encryption"
 	"github.com/dashboard-platform/template-service/internal/handler"
 	"github.com/dashboard-platform/template-service/internal/logger"
 	"github.com/dashboard-platform/template-service/internal/middleware"
+	"github.com/dashboard-platform/template-service/internal/retention"
+	"github.com/dashboard-platform/template-service/internal/webhook"
 	"github.com/rs/zerolog/log"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	_ "github.com/joho/godotenv/autoload"
 )
 
@@ -20,12 +27,34 @@ func main() {
 	// Load the configuration from environment variables.
 	c, err := config.Load()
 	if err != nil {
+		log.Error().Err(err).Msg("failed to load configuration")
 		return
 	}
 
 	// Initialize the logger with the loaded configuration
 	baseLogger := logger.Init(c.Env)
 	httpLogger := logger.NewComponentLogger(baseLogger, "http")
+	authLogger := logger.NewComponentLogger(baseLogger, "auth")
+
+	var verifier *auth.Verifier
+	if c.JWTSecret != "" || len(c.JWTPublicKeyFiles) > 0 || c.JWKSFile != "" {
+		verifier, err = auth.NewVerifier(auth.Config{
+			HS256Secret:   c.JWTSecret,
+			RS256KeyFiles: c.JWTPublicKeyFiles,
+			JWKSFile:      c.JWKSFile,
+			Issuer:        c.JWTIssuer,
+			Audience:      c.JWTAudience,
+			Leeway:        30 * time.Second,
+		})
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to load JWT keys")
+			return
+		}
+	}
+
+	if c.TrustedGateway {
+		log.Warn().Msg("trusted gateway mode enabled: X-User-ID headers are accepted without a token")
+	}
 
 	db, err := database.Init(c.DSN, baseLogger)
 	if err != nil {
@@ -33,39 +62,108 @@ func main() {
 		return
 	}
 
+	// Render values in the history and webhook signing secrets share the key.
+	var historyCipher *encryption.Cipher
+	if c.HistoryEncryptionKey != nil {
+		if historyCipher, err = encryption.New(c.HistoryEncryptionKey); err != nil {
+			log.Fatal().Err(err).Msg("failed to set up history encryption")
+			return
+		}
+	}
+	db.SetCipher(historyCipher)
+
 	if err := db.AutoMigrate(); err != nil {
 		log.Fatal().Err(err).Msg("failed to run migrations")
 		return
 	}
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// Write every error as a problem document.
+		ErrorHandler: handler.ErrorHandler,
+	})
 	// Middlewares
 	app.Use(
+		// Tag each request with an ID that is logged and returned in errors.
+		requestid.New(),
+
 		// Add security headers.
 		helmet.New(),
 
 		// Add custom request logger middleware.
 		middleware.RequestLogger(httpLogger),
+
+		// Verify API keys; routes accept them through middleware.AllowAPIKey.
+		middleware.APIKeyAuth(middleware.APIKeyConfig{
+			Verifier: db,
+			Logger:   authLogger,
+		}),
+
+		// Identify the caller from a bearer token (or a trusted gateway header).
+		middleware.Authenticate(middleware.AuthConfig{
+			Verifier:       verifier,
+			TrustedGateway: c.TrustedGateway,
+			Next: func(ctx *fiber.Ctx) bool {
+				// Share links carry their own signed token; the API description is public.
+				return middleware.HasAPIKey(ctx) || strings.HasPrefix(ctx.Path(), "/shared/") ||
+					ctx.Path() == "/openapi.json"
+			},
+			Logger: authLogger,
+		}),
 	)
 
 	globalLimiter := limiter.New(limiter.Config{
-		Max:        20,
-		Expiration: 1 * time.Minute,
+		Max:          20,
+		Expiration:   1 * time.Minute,
+		LimitReached: limitReached,
+	})
+
+	previewLimiter := limiter.New(limiter.Config{
+		Max:          1000,
+		Expiration:   1 * time.Minute,
+		LimitReached: limitReached,
+	})
+
+	// API keys may only read templates and render previews.
+	keyRead := middleware.AllowAPIKey(database.APIKeyScopeRead)
+	keyRender := middleware.AllowAPIKey(database.APIKeyScopeRender)
+
+	// Retried creates and renders replay their first response.
+	idempotent := middleware.Idempotency(middleware.IdempotencyConfig{
+		Store:  db,
+		TTL:    c.IdempotencyTTL,
+		Logger: httpLogger,
+	})
+
+	retentionRules := database.RetentionRules{
+		MaxAgeDays: c.HistoryRetentionDays,
+		KeepLast:   c.HistoryKeepLast,
+	}
+
+	// Purge expired render history, idempotency records and webhook deliveries in the background.
+	retention.Start(context.Background(), db, retention.Intervals{
+		History: c.HistoryPurgeInterval,
+		Cleanup: c.CleanupInterval,
+	}, retentionRules,
+		logger.NewComponentLogger(baseLogger, "retention"))
+
+	// Deliver webhook events from the outbox in the background.
+	webhook.Start(context.Background(), db, c.WebhookInterval,
+		logger.NewComponentLogger(baseLogger, "webhooks"))
+
+	h := handler.New(db, handler.Options{
+		ShareLinkSecret: []byte(c.ShareLinkSecret),
+		HistoryCipher:   historyCipher,
+		Retention:       retentionRules,
+	})
+
+	registerRoutes(app, &h, routeMiddleware{
+		limit:      globalLimiter,
+		preview:    previewLimiter,
+		keyRead:    keyRead,
+		keyRender:  keyRender,
+		idempotent: idempotent,
 	})
 
-	h := handler.New(db)
-
-	app.Post("/templates", globalLimiter, h.CreateTemplate)
-	app.Get("/templates", globalLimiter, h.GetTemplates)
-	app.Post("/templates/history", globalLimiter, h.CreateHistory)
-	app.Get("/templates/history", globalLimiter, h.GetHistory)
-	app.Get("/templates/:id", globalLimiter, h.GetTemplateByID)
-	app.Post("/templates/:id/update", globalLimiter, h.UpdateTemplate)
-	app.Post("/templates/:id/delete", globalLimiter, h.DeleteTemplate)
-	app.Post("/templates/:id/preview", limiter.New(limiter.Config{
-		Max:        1000,
-		Expiration: 1 * time.Minute,
-	}), h.PreviewTemplate)
 	// Start the HTTP server.
 	log.Info().Msgf("Template Service started on %s", c.Port)
 	if err = app.Listen(c.Port); err != nil {
@@ -73,3 +171,8 @@ func main() {
 		return
 	}
 }
+
+// limitReached reports rate limited requests through the error handler.
+func limitReached(*fiber.Ctx) error {
+	return fiber.ErrTooManyRequests
+}
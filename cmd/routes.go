@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/dashboard-platform/template-service/internal/handler"
+	"github.com/dashboard-platform/template-service/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// routeMiddleware holds the middlewares shared between routes.
+type routeMiddleware struct {
+	limit      fiber.Handler // global rate limit
+	preview    fiber.Handler // rate limit of renders
+	keyRead    fiber.Handler // accept API keys with the read scope
+	keyRender  fiber.Handler // accept API keys with the render scope
+	idempotent fiber.Handler // replay retried requests
+}
+
+// registerRoutes registers every route of the service. Each route must also be
+// described in internal/openapi.
+func registerRoutes(app *fiber.App, h *handler.HTTPHandler, mw routeMiddleware) {
+	app.Get("/openapi.json", mw.limit, h.GetOpenAPI)
+
+	app.Post("/templates", mw.limit, mw.idempotent, h.CreateTemplate)
+	app.Get("/templates", mw.limit, mw.keyRead, h.GetTemplates)
+	app.Get("/templates/export", mw.limit, mw.keyRead, h.ExportTemplates)
+	app.Post("/templates/import", mw.limit, mw.idempotent, h.ImportTemplates)
+	app.Get("/templates/history", mw.limit, h.GetHistory)
+	app.Post("/templates/history", mw.limit, middleware.Deprecated("/templates/:id/preview"), h.CreateHistory)
+	app.Post("/templates/history/:id/rerender", mw.limit, h.RerenderHistory)
+	app.Get("/templates/search", mw.limit, mw.keyRead, h.SearchTemplates)
+	app.Get("/templates/recent", mw.limit, h.GetRecentTemplates)
+	app.Get("/templates/favorites", mw.limit, h.GetFavorites)
+	app.Get("/templates/:id", mw.limit, mw.keyRead, h.GetTemplateByID)
+	app.Put("/templates/:id", mw.limit, h.ReplaceTemplate)
+	app.Patch("/templates/:id", mw.limit, h.PatchTemplate)
+	app.Delete("/templates/:id", mw.limit, h.RemoveTemplate)
+	app.Post("/templates/:id/update", mw.limit, middleware.Deprecated("/templates/:id"), h.UpdateTemplate)
+	app.Post("/templates/:id/delete", mw.limit, middleware.Deprecated("/templates/:id"), h.DeleteTemplate)
+	app.Post("/templates/:id/publish", mw.limit, h.PublishTemplate)
+	app.Post("/templates/:id/unpublish", mw.limit, h.UnpublishTemplate)
+	app.Post("/templates/:id/clone", mw.limit, h.CloneTemplate)
+	app.Get("/templates/:id/export", mw.limit, mw.keyRead, h.ExportTemplate)
+	app.Get("/templates/:id/schema", mw.limit, mw.keyRead, h.GetTemplateSchema)
+	app.Get("/templates/:id/history", mw.limit, h.GetTemplateHistory)
+	app.Get("/templates/:id/stats", mw.limit, h.GetTemplateStats)
+	app.Get("/templates/:id/upstream", mw.limit, mw.keyRead, h.GetUpstream)
+	app.Post("/templates/:id/upstream/pull", mw.limit, h.PullUpstream)
+	app.Post("/templates/:id/shares", mw.limit, h.GrantShare)
+	app.Get("/templates/:id/shares", mw.limit, h.GetShares)
+	app.Post("/templates/:id/shares/:userId/delete", mw.limit, h.RevokeShare)
+	app.Post("/templates/:id/preview", mw.preview, mw.keyRender, mw.idempotent, h.PreviewTemplate)
+
+	app.Post("/templates/:id/share-links", mw.limit, h.CreateShareLink)
+	app.Post("/templates/:id/tags", mw.limit, h.SetTemplateTags)
+	app.Post("/templates/:id/move", mw.limit, h.MoveTemplate)
+	app.Post("/templates/:id/favorite", mw.limit, h.FavoriteTemplate)
+	app.Post("/templates/:id/unfavorite", mw.limit, h.UnfavoriteTemplate)
+	app.Get("/shared/:token", mw.limit, h.GetSharedTemplate)
+	app.Post("/shared/:token/render", mw.limit, mw.idempotent, h.RenderSharedTemplate)
+
+	app.Post("/catalogs", mw.limit, h.CreateCatalog)
+	app.Get("/catalogs", mw.limit, mw.keyRead, h.GetCatalogs)
+	app.Get("/catalogs/:id", mw.limit, mw.keyRead, h.GetCatalogByID)
+	app.Post("/catalogs/:id/update", mw.limit, h.UpdateCatalog)
+	app.Post("/catalogs/:id/delete", mw.limit, h.DeleteCatalog)
+
+	app.Post("/tags", mw.limit, h.CreateTag)
+	app.Get("/tags", mw.limit, mw.keyRead, h.GetTags)
+	app.Post("/tags/:id/delete", mw.limit, h.DeleteTag)
+
+	app.Post("/folders", mw.limit, h.CreateFolder)
+	app.Get("/folders", mw.limit, mw.keyRead, h.GetFolders)
+	app.Post("/folders/:id/update", mw.limit, h.UpdateFolder)
+	app.Post("/folders/:id/delete", mw.limit, h.DeleteFolder)
+
+	app.Get("/stats/usage", mw.limit, h.GetUsageStats)
+
+	app.Get("/retention", mw.limit, h.GetRetentionPolicy)
+	app.Post("/retention", mw.limit, h.SetRetentionPolicy)
+	app.Post("/retention/delete", mw.limit, h.DeleteRetentionPolicy)
+	app.Get("/retention/dry-run", mw.limit, h.PreviewHistoryPurge)
+
+	app.Get("/gallery", mw.limit, h.GetGallery)
+	app.Get("/gallery/:id", mw.limit, h.GetGalleryTemplate)
+
+	app.Post("/orgs", mw.limit, h.CreateOrganization)
+	app.Get("/orgs", mw.limit, h.GetOrganizations)
+	app.Get("/orgs/:id/members", mw.limit, h.GetOrgMembers)
+	app.Post("/orgs/:id/members", mw.limit, h.SetOrgMember)
+	app.Post("/orgs/:id/members/:userId/delete", mw.limit, h.RemoveOrgMember)
+
+	app.Post("/api-keys", mw.limit, h.CreateAPIKey)
+	app.Get("/api-keys", mw.limit, h.GetAPIKeys)
+	app.Delete("/api-keys/:id", mw.limit, h.DeleteAPIKey)
+
+	app.Post("/webhooks", mw.limit, h.CreateWebhook)
+	app.Get("/webhooks", mw.limit, h.GetWebhooks)
+	app.Delete("/webhooks/:id", mw.limit, h.DeleteWebhook)
+	app.Get("/webhooks/:id/deliveries", mw.limit, h.GetWebhookDeliveries)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dashboard-platform/template-service/internal/handler"
+	"github.com/dashboard-platform/template-service/internal/openapi"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutesDocumented(t *testing.T) {
+	next := func(c *fiber.Ctx) error { return c.Next() }
+
+	app := fiber.New()
+	registerRoutes(app, &handler.HTTPHandler{}, routeMiddleware{
+		limit:      next,
+		preview:    next,
+		keyRead:    next,
+		keyRender:  next,
+		idempotent: next,
+	})
+
+	spec := openapi.Spec()
+	registered := make(map[string]bool)
+	for _, route := range app.GetRoutes(true) {
+		// Fiber answers HEAD for every GET route.
+		if route.Method == http.MethodHead {
+			continue
+		}
+
+		path, method := openapi.Path(route.Path), strings.ToLower(route.Method)
+		registered[method+" "+path] = true
+		require.Contains(t, spec.Paths, path, "route %s %s is missing from the OpenAPI document", route.Method, route.Path)
+		require.Contains(t, spec.Paths[path], method, "route %s %s is missing from the OpenAPI document", route.Method, route.Path)
+	}
+
+	for path, item := range spec.Paths {
+		for method := range item {
+			require.True(t, registered[method+" "+path], "documented route %s %s is not registered", method, path)
+		}
+	}
+}
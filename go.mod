@@ -3,6 +3,7 @@ module github.com/dashboard-platform/template-service
 go 1.23.1
 
 require (
+	github.com/aymerick/raymond v2.0.2+incompatible
 	github.com/gofiber/fiber/v2 v2.52.6
 	github.com/google/uuid v1.6.0
 	github.com/rs/zerolog v1.34.0
@@ -13,7 +14,6 @@ require (
 )
 
 require (
-	github.com/aymerick/raymond v2.0.2+incompatible // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
 )
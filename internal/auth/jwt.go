@@ -0,0 +1,284 @@
+// Package auth verifies the identity of callers. It validates HS256 and RS256
+// signed JSON Web Tokens against keys loaded from configuration, and signs the
+// tokens of share links that grant access to a single template.
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned for any token that fails verification.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Config describes the keys and claims a Verifier accepts.
+type Config struct {
+	HS256Secret   string        // Shared secret for HS256 tokens.
+	RS256KeyFiles []string      // PEM files with RSA public keys for RS256 tokens.
+	JWKSFile      string        // JWKS document with RSA public keys for RS256 tokens.
+	Issuer        string        // Required "iss" claim, ignored when empty.
+	Audience      string        // Required "aud" entry, ignored when empty.
+	Leeway        time.Duration // Allowed clock skew for "exp" and "nbf".
+}
+
+// Claims are the registered claims of a verified token.
+type Claims struct {
+	Subject   string
+	Issuer    string
+	Audience  []string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+}
+
+// rsaKey is an RSA public key with its optional key ID.
+type rsaKey struct {
+	kid string
+	key *rsa.PublicKey
+}
+
+// Verifier validates bearer tokens.
+type Verifier struct {
+	hmacSecret []byte
+	rsaKeys    []rsaKey
+	issuer     string
+	audience   string
+	leeway     time.Duration
+	now        func() time.Time
+}
+
+// NewVerifier loads the configured keys and returns a Verifier.
+//
+// Parameters:
+//   - cfg: The accepted keys and claims.
+//
+// Returns:
+//   - *Verifier: The token verifier.
+//   - error: An error if a key file cannot be loaded or no key is configured.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	v := &Verifier{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+		now:      time.Now,
+	}
+
+	if cfg.HS256Secret != "" {
+		v.hmacSecret = []byte(cfg.HS256Secret)
+	}
+
+	for _, path := range cfg.RS256KeyFiles {
+		key, err := LoadRSAPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		v.rsaKeys = append(v.rsaKeys, rsaKey{key: key})
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		for kid, key := range keys {
+			v.rsaKeys = append(v.rsaKeys, rsaKey{kid: kid, key: key})
+		}
+	}
+
+	if v.hmacSecret == nil && len(v.rsaKeys) == 0 {
+		return nil, errors.New("no JWT verification keys configured")
+	}
+
+	return v, nil
+}
+
+// Verify checks the signature and registered claims of a compact JWS token.
+//
+// Parameters:
+//   - token: The encoded token.
+//
+// Returns:
+//   - Claims: The verified claims.
+//   - error: An error wrapping ErrInvalidToken if verification fails.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	if err := v.verifySignature(header.Alg, header.Kid, signed, signature); err != nil {
+		return Claims{}, err
+	}
+
+	var payload struct {
+		Sub string          `json:"sub"`
+		Iss string          `json:"iss"`
+		Aud json.RawMessage `json:"aud"`
+		Exp *json.Number    `json:"exp"`
+		Nbf *json.Number    `json:"nbf"`
+		Iat *json.Number    `json:"iat"`
+	}
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return Claims{}, fmt.Errorf("%w: malformed payload", ErrInvalidToken)
+	}
+
+	claims := Claims{
+		Subject: payload.Sub,
+		Issuer:  payload.Iss,
+	}
+
+	if claims.Audience, err = parseAudience(payload.Aud); err != nil {
+		return Claims{}, err
+	}
+
+	now := v.now()
+
+	if payload.Exp == nil {
+		return Claims{}, fmt.Errorf("%w: missing exp", ErrInvalidToken)
+	}
+	if claims.ExpiresAt, err = numericDate(*payload.Exp); err != nil {
+		return Claims{}, err
+	}
+	if now.After(claims.ExpiresAt.Add(v.leeway)) {
+		return Claims{}, fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+
+	if payload.Nbf != nil {
+		nbf, err := numericDate(*payload.Nbf)
+		if err != nil {
+			return Claims{}, err
+		}
+		if now.Add(v.leeway).Before(nbf) {
+			return Claims{}, fmt.Errorf("%w: token not yet valid", ErrInvalidToken)
+		}
+	}
+
+	if payload.Iat != nil {
+		if claims.IssuedAt, err = numericDate(*payload.Iat); err != nil {
+			return Claims{}, err
+		}
+	}
+
+	if claims.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: missing sub", ErrInvalidToken)
+	}
+
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return Claims{}, fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return Claims{}, fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(alg, kid string, signed, signature []byte) error {
+	switch alg {
+	case "HS256":
+		if v.hmacSecret == nil {
+			return fmt.Errorf("%w: HS256 is not accepted", ErrInvalidToken)
+		}
+		mac := hmac.New(sha256.New, v.hmacSecret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+		return nil
+	case "RS256":
+		digest := sha256.Sum256(signed)
+		for _, k := range v.candidateKeys(kid) {
+			if rsa.VerifyPKCS1v15(k.key, crypto.SHA256, digest[:], signature) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+	}
+
+	return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+}
+
+// candidateKeys returns the keys matching kid, or every key when none matches.
+func (v *Verifier) candidateKeys(kid string) []rsaKey {
+	if kid != "" {
+		var matched []rsaKey
+		for _, k := range v.rsaKeys {
+			if k.kid == kid {
+				matched = append(matched, k)
+			}
+		}
+		if len(matched) > 0 {
+			return matched
+		}
+	}
+
+	var unnamed []rsaKey
+	for _, k := range v.rsaKeys {
+		if k.kid == "" || kid == "" {
+			unnamed = append(unnamed, k)
+		}
+	}
+	return unnamed
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func parseAudience(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("%w: malformed aud", ErrInvalidToken)
+	}
+	return list, nil
+}
+
+func numericDate(n json.Number) (time.Time, error) {
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: malformed date claim", ErrInvalidToken)
+	}
+
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), nil
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]any) string {
+	t.Helper()
+	unsigned := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	unsigned := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// TestVerifyHS256 verifies signature and claim checks for HMAC tokens.
+func TestVerifyHS256(t *testing.T) {
+	v, err := NewVerifier(Config{HS256Secret: "secret", Issuer: "auth-service", Audience: "templates"})
+	require.NoError(t, err)
+
+	valid := map[string]any{
+		"sub": "user-1",
+		"iss": "auth-service",
+		"aud": []string{"templates", "other"},
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	claims, err := v.Verify(signHS256(t, "secret", valid))
+	require.NoError(t, err)
+	require.Equal(t, "user-1", claims.Subject)
+
+	tests := []struct {
+		name   string
+		token  string
+		mutate func(map[string]any)
+	}{
+		{name: "wrong secret", token: signHS256(t, "other", valid)},
+		{name: "expired", mutate: func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{name: "missing exp", mutate: func(c map[string]any) { delete(c, "exp") }},
+		{name: "not yet valid", mutate: func(c map[string]any) { c["nbf"] = time.Now().Add(time.Hour).Unix() }},
+		{name: "wrong issuer", mutate: func(c map[string]any) { c["iss"] = "evil" }},
+		{name: "wrong audience", mutate: func(c map[string]any) { c["aud"] = "billing" }},
+		{name: "missing subject", mutate: func(c map[string]any) { delete(c, "sub") }},
+		{name: "malformed", token: "abc.def"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := tt.token
+			if tt.mutate != nil {
+				c := map[string]any{}
+				for k, v := range valid {
+					c[k] = v
+				}
+				tt.mutate(c)
+				token = signHS256(t, "secret", c)
+			}
+
+			_, err := v.Verify(token)
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+// TestVerifyRS256 verifies RSA tokens against PEM and JWKS keys.
+func TestVerifyRS256(t *testing.T) {
+	dir := t.TempDir()
+
+	pemKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&pemKey.PublicKey)
+	require.NoError(t, err)
+	pemPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	jwksKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	jwks := map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(jwksKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(jwksKey.E)).Bytes()),
+	}}}
+	raw, err := json.Marshal(jwks)
+	require.NoError(t, err)
+	jwksPath := filepath.Join(dir, "jwks.json")
+	require.NoError(t, os.WriteFile(jwksPath, raw, 0o600))
+
+	v, err := NewVerifier(Config{RS256KeyFiles: []string{pemPath}, JWKSFile: jwksPath})
+	require.NoError(t, err)
+
+	claims := map[string]any{"sub": "user-2", "exp": time.Now().Add(time.Hour).Unix()}
+
+	_, err = v.Verify(signRS256(t, pemKey, "", claims))
+	require.NoError(t, err)
+
+	_, err = v.Verify(signRS256(t, jwksKey, "k1", claims))
+	require.NoError(t, err)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = v.Verify(signRS256(t, other, "k1", claims))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// HS256 must not be accepted when only RSA keys are configured.
+	_, err = v.Verify(signHS256(t, "secret", claims))
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+// TestNewVerifierRequiresKeys verifies that a verifier cannot be built without keys.
+func TestNewVerifierRequiresKeys(t *testing.T) {
+	_, err := NewVerifier(Config{})
+	require.Error(t, err)
+}
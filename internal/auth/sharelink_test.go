@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShareLinkRoundTrip verifies that signed share links verify until they expire.
+func TestShareLinkRoundTrip(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	link := ShareLink{
+		TemplateID: uuid.New(),
+		Version:    3,
+		Actions:    []string{ShareActionRender},
+		ExpiresAt:  now.Add(time.Hour),
+	}
+
+	token, err := SignShareLink(secret, link)
+	require.NoError(t, err)
+
+	got, err := VerifyShareLink(secret, token, now)
+	require.NoError(t, err)
+	require.Equal(t, link.TemplateID, got.TemplateID)
+	require.Equal(t, 3, got.Version)
+	require.True(t, got.Allows(ShareActionRender))
+	require.False(t, got.Allows(ShareActionView))
+
+	_, err = VerifyShareLink(secret, token, now.Add(2*time.Hour))
+	require.ErrorIs(t, err, ErrInvalidShareLink)
+}
+
+// TestShareLinkTampering verifies that modified or foreign tokens are rejected.
+func TestShareLinkTampering(t *testing.T) {
+	secret := []byte("secret")
+	now := time.Now()
+
+	token, err := SignShareLink(secret, ShareLink{
+		TemplateID: uuid.New(),
+		Version:    1,
+		Actions:    []string{ShareActionView},
+		ExpiresAt:  now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	forged, err := SignShareLink([]byte("other"), ShareLink{
+		TemplateID: uuid.New(),
+		Version:    1,
+		Actions:    []string{ShareActionRender},
+		ExpiresAt:  now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	payload, sig, _ := strings.Cut(token, ".")
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "wrong secret", token: forged},
+		{name: "swapped payload", token: forgedPayload + "." + sig},
+		{name: "missing signature", token: payload},
+		{name: "garbage", token: "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyShareLink(secret, tt.token, now)
+			require.ErrorIs(t, err, ErrInvalidShareLink)
+		})
+	}
+
+	_, err = SignShareLink(nil, ShareLink{})
+	require.Error(t, err)
+}
@@ -0,0 +1,470 @@
+// Package bundle reads and writes portable template bundles, used to move templates
+// between environments. A bundle is a zip archive with a manifest.json describing
+// its templates, one content file per version, the fields and sample data of each
+// template, and the message catalogs the templates depend on.
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/jsonschema"
+	"github.com/dashboard-platform/template-service/models"
+)
+
+const (
+	// Format identifies bundles in their manifest.
+	Format = "template-bundle"
+	// FormatVersion is the bundle format version written by this package. Bundles
+	// of newer versions are rejected.
+	FormatVersion = 1
+
+	manifestPath = "manifest.json"
+	maxFileSize  = 8 << 20  // largest file read from a bundle
+	maxReadSize  = 32 << 20 // largest total size of the files read from a bundle
+	maxTemplates = 500      // most templates in a bundle
+	maxVersions  = 5000     // most versions of all templates in a bundle
+)
+
+// Bundle is the content of a bundle.
+type Bundle struct {
+	CreatedAt time.Time
+	Templates []Template
+	Catalogs  []Catalog
+}
+
+type Template struct {
+	ID          string // ID in the exporting environment
+	Name        string
+	Description string
+	Type        string
+	Category    string
+	Tags        []string
+	CatalogID   string    // ID of a catalog in the bundle, or empty
+	Partials    []string  // names of the partials the content references
+	Versions    []Version // oldest first
+	Fields      []models.FieldDTO
+	Sample      map[string]any // values the fields accept, generated on export
+}
+
+type Version struct {
+	Version   int
+	Content   string
+	CreatedAt time.Time
+}
+
+type Catalog struct {
+	ID            string // ID in the exporting environment
+	Name          string
+	DefaultLocale string
+	Messages      map[string]map[string]string
+}
+
+type manifest struct {
+	Format    string             `json:"format"`
+	Version   int                `json:"version"`
+	CreatedAt time.Time          `json:"created_at"`
+	Templates []manifestTemplate `json:"templates"`
+	Catalogs  []manifestCatalog  `json:"catalogs"`
+}
+
+type manifestTemplate struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Type        string            `json:"type"`
+	Category    string            `json:"category"`
+	Tags        []string          `json:"tags"`
+	Catalog     string            `json:"catalog,omitempty"`
+	Partials    []string          `json:"partials"`
+	Versions    []manifestVersion `json:"versions"`
+	Fields      string            `json:"fields"`      // path of the fields file
+	SampleData  string            `json:"sample_data"` // path of the sample data file
+}
+
+type manifestVersion struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Path      string    `json:"path"`
+}
+
+type manifestCatalog struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	DefaultLocale string `json:"default_locale"`
+	Path          string `json:"path"` // path of the messages file
+}
+
+// partialPattern matches partial calls and partial blocks such as {{> header}}
+// and {{#> layout}}.
+var partialPattern = regexp.MustCompile(`\{\{~?#?>\s*([\w./-]+)`)
+
+// Partials returns the names of the partials referenced by content, sorted. The
+// service does not register partials itself; bundles list them so that importers
+// can tell which dependencies the content expects.
+func Partials(content string) []string {
+	names := []string{}
+	for _, match := range partialPattern.FindAllStringSubmatch(content, -1) {
+		if !slices.Contains(names, match[1]) {
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New assembles a bundle from templates and the catalogs they reference.
+//
+// Parameters:
+//   - templates: The templates, loaded with their versions, fields and tags.
+//   - catalogs: The message catalogs referenced by the templates.
+//   - now: The creation time of the bundle.
+//
+// Returns:
+//   - Bundle: The bundle.
+//   - error: An error if stored fields or catalog messages cannot be decoded.
+func New(templates []models.Template, catalogs []models.MessageCatalog, now time.Time) (Bundle, error) {
+	b := Bundle{CreatedAt: now}
+
+	for _, c := range catalogs {
+		catalog := Catalog{ID: c.ID.String(), Name: c.Name, DefaultLocale: c.DefaultLocale}
+		if len(c.Messages) > 0 {
+			if err := json.Unmarshal(c.Messages, &catalog.Messages); err != nil {
+				return Bundle{}, fmt.Errorf("decoding messages of catalog %s: %w", c.ID, err)
+			}
+		}
+		b.Catalogs = append(b.Catalogs, catalog)
+	}
+
+	for _, t := range templates {
+		dto := t.ToDTO()
+		template := Template{
+			ID:          dto.ID,
+			Name:        dto.Name,
+			Description: dto.Description,
+			Type:        dto.Type,
+			Category:    dto.Category,
+			Tags:        dto.Tags,
+			Partials:    []string{},
+			Fields:      dto.Fields,
+		}
+		if t.CatalogID != nil {
+			template.CatalogID = t.CatalogID.String()
+		}
+
+		versions := slices.Clone(t.Versions)
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+		for _, v := range versions {
+			template.Versions = append(template.Versions, Version{
+				Version:   v.Version,
+				Content:   v.Content,
+				CreatedAt: v.CreatedAt,
+			})
+			for _, name := range Partials(v.Content) {
+				if !slices.Contains(template.Partials, name) {
+					template.Partials = append(template.Partials, name)
+				}
+			}
+		}
+		sort.Strings(template.Partials)
+
+		schema, err := jsonschema.Template(dto, "")
+		if err != nil {
+			return Bundle{}, fmt.Errorf("describing fields of template %s: %w", t.ID, err)
+		}
+		template.Sample, _ = jsonschema.Example(schema).(map[string]any)
+
+		b.Templates = append(b.Templates, template)
+	}
+
+	return b, nil
+}
+
+// Write encodes a bundle as a zip archive.
+//
+// Parameters:
+//   - w: The destination of the archive.
+//   - b: The bundle.
+//
+// Returns:
+//   - error: An error if writing fails.
+func Write(w io.Writer, b Bundle) error {
+	zw := zip.NewWriter(w)
+
+	m := manifest{
+		Format:    Format,
+		Version:   FormatVersion,
+		CreatedAt: b.CreatedAt,
+		Templates: []manifestTemplate{},
+		Catalogs:  []manifestCatalog{},
+	}
+	files := map[string][]byte{}
+
+	add := func(path string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		files[path] = data
+		return nil
+	}
+
+	for _, c := range b.Catalogs {
+		path := "catalogs/" + c.ID + ".json"
+		m.Catalogs = append(m.Catalogs, manifestCatalog{
+			ID:            c.ID,
+			Name:          c.Name,
+			DefaultLocale: c.DefaultLocale,
+			Path:          path,
+		})
+		if err := add(path, c.Messages); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range b.Templates {
+		dir := "templates/" + t.ID + "/"
+		entry := manifestTemplate{
+			ID:          t.ID,
+			Name:        t.Name,
+			Description: t.Description,
+			Type:        t.Type,
+			Category:    t.Category,
+			Tags:        t.Tags,
+			Catalog:     t.CatalogID,
+			Partials:    t.Partials,
+			Fields:      dir + "fields.json",
+			SampleData:  dir + "sample.json",
+		}
+
+		for _, v := range t.Versions {
+			path := fmt.Sprintf("%sversions/%d.hbs", dir, v.Version)
+			entry.Versions = append(entry.Versions, manifestVersion{
+				Version:   v.Version,
+				CreatedAt: v.CreatedAt,
+				Path:      path,
+			})
+			files[path] = []byte(v.Content)
+		}
+
+		if err := add(entry.Fields, t.Fields); err != nil {
+			return err
+		}
+		if err := add(entry.SampleData, t.Sample); err != nil {
+			return err
+		}
+		m.Templates = append(m.Templates, entry)
+	}
+
+	if err := add(manifestPath, m); err != nil {
+		return err
+	}
+
+	// The manifest comes first, followed by the other files in path order.
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		if path != manifestPath {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range append([]string{manifestPath}, paths...) {
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     path,
+			Method:   zip.Deflate,
+			Modified: b.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(files[path]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// Read decodes a bundle and checks that it is consistent. The returned errors
+// describe the problem with the bundle and are safe to show to clients. Since
+// archives compress well, Read bounds what it decompresses: the number of
+// templates and versions, the size of each file and of all files together, and
+// each file may be referenced only once.
+//
+// Parameters:
+//   - data: The zip archive.
+//
+// Returns:
+//   - Bundle: The bundle.
+//   - error: An error if the archive is not a valid bundle.
+func Read(data []byte) (Bundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return Bundle{}, fmt.Errorf("reading archive: %w", err)
+	}
+	r := &reader{zr: zr, read: map[string]bool{}, remaining: maxReadSize}
+
+	var m manifest
+	if err := r.readJSON(manifestPath, &m); err != nil {
+		return Bundle{}, err
+	}
+	if m.Format != Format {
+		return Bundle{}, fmt.Errorf("%s is not a %s manifest", manifestPath, Format)
+	}
+	if m.Version < 1 || m.Version > FormatVersion {
+		return Bundle{}, fmt.Errorf("unsupported bundle version %d", m.Version)
+	}
+	if len(m.Templates) > maxTemplates {
+		return Bundle{}, fmt.Errorf("bundle has more than %d templates", maxTemplates)
+	}
+	versions := 0
+	for _, t := range m.Templates {
+		versions += len(t.Versions)
+	}
+	if versions > maxVersions {
+		return Bundle{}, fmt.Errorf("bundle has more than %d versions", maxVersions)
+	}
+
+	b := Bundle{CreatedAt: m.CreatedAt}
+
+	catalogs := map[string]bool{}
+	for _, c := range m.Catalogs {
+		if c.ID == "" || catalogs[c.ID] {
+			return Bundle{}, fmt.Errorf("catalog %q: missing or duplicate ID", c.ID)
+		}
+		catalogs[c.ID] = true
+
+		catalog := Catalog{ID: c.ID, Name: c.Name, DefaultLocale: c.DefaultLocale}
+		if err := r.readJSON(c.Path, &catalog.Messages); err != nil {
+			return Bundle{}, fmt.Errorf("catalog %q: %w", c.ID, err)
+		}
+		b.Catalogs = append(b.Catalogs, catalog)
+	}
+
+	templates := map[string]bool{}
+	for _, t := range m.Templates {
+		if templates[t.ID] {
+			return Bundle{}, fmt.Errorf("template %q: duplicate ID", t.ID)
+		}
+		templates[t.ID] = true
+
+		template, err := r.readTemplate(t, catalogs)
+		if err != nil {
+			return Bundle{}, fmt.Errorf("template %q: %w", t.ID, err)
+		}
+
+		b.Templates = append(b.Templates, template)
+	}
+
+	return b, nil
+}
+
+func (r *reader) readTemplate(t manifestTemplate, catalogs map[string]bool) (Template, error) {
+	if t.ID == "" {
+		return Template{}, errors.New("missing ID")
+	}
+	if t.Catalog != "" && !catalogs[t.Catalog] {
+		return Template{}, fmt.Errorf("catalog %q is not in the bundle", t.Catalog)
+	}
+	if len(t.Versions) == 0 {
+		return Template{}, errors.New("no versions")
+	}
+
+	template := Template{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Type:        t.Type,
+		Category:    t.Category,
+		Tags:        t.Tags,
+		CatalogID:   t.Catalog,
+		Partials:    t.Partials,
+	}
+
+	previous := 0
+	for _, v := range t.Versions {
+		if v.Version <= previous {
+			return Template{}, fmt.Errorf("version %d is out of order", v.Version)
+		}
+		previous = v.Version
+
+		content, err := r.readFile(v.Path)
+		if err != nil {
+			return Template{}, fmt.Errorf("version %d: %w", v.Version, err)
+		}
+		template.Versions = append(template.Versions, Version{
+			Version:   v.Version,
+			Content:   string(content),
+			CreatedAt: v.CreatedAt,
+		})
+	}
+
+	if err := r.readJSON(t.Fields, &template.Fields); err != nil {
+		return Template{}, fmt.Errorf("fields: %w", err)
+	}
+	if t.SampleData != "" {
+		if err := r.readJSON(t.SampleData, &template.Sample); err != nil {
+			return Template{}, fmt.Errorf("sample data: %w", err)
+		}
+	}
+
+	return template, nil
+}
+
+// reader reads the files of a bundle archive and keeps track of what was read.
+type reader struct {
+	zr        *zip.Reader
+	read      map[string]bool // paths read so far
+	remaining int             // bytes left to read
+}
+
+func (r *reader) readJSON(path string, v any) error {
+	data, err := r.readFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return nil
+}
+
+// readFile reads a file of the archive. Paths escaping the archive are rejected by
+// zip.Reader.Open, which only accepts clean paths, so a file referenced twice is
+// always referenced by the same path.
+func (r *reader) readFile(path string) ([]byte, error) {
+	if r.read[path] {
+		return nil, fmt.Errorf("%s is referenced more than once", path)
+	}
+	r.read[path] = true
+
+	f, err := r.zr.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %q: %w", path, err)
+	}
+	defer f.Close()
+
+	limit := min(maxFileSize, r.remaining)
+	data, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if len(data) > maxFileSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", path, maxFileSize)
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("bundle is larger than %d bytes uncompressed", maxReadSize)
+	}
+	r.remaining -= len(data)
+
+	return data, nil
+}
@@ -0,0 +1,247 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestPartials(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected []string
+	}{
+		{"<p>{{name}}</p>", []string{}},
+		{"{{> header}}{{> footer }}{{>header}}", []string{"footer", "header"}},
+		{"{{#> layouts/base title=name}}body{{/layouts/base}}", []string{"layouts/base"}},
+		{"{{~> row-item}}", []string{"row-item"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			require.Equal(t, tt.expected, Partials(tt.content))
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	catalogID := uuid.New()
+	templateID := uuid.New()
+
+	templates := []models.Template{{
+		ID:          templateID,
+		Name:        "Invoice",
+		Description: "Monthly invoice",
+		Type:        "html",
+		Category:    "billing",
+		CatalogID:   &catalogID,
+		Versions: []models.TemplateVersion{
+			{Version: 2, Content: `{{> header}}<p>{{t "total"}} {{amount}}</p>`, CreatedAt: now},
+			{Version: 1, Content: "<p>{{amount}}</p>", CreatedAt: now.Add(-time.Hour)},
+		},
+		Fields: []models.TemplateField{
+			{Key: "amount", Label: "Amount", Type: "number", Required: true},
+			{Key: "currency", Label: "Currency", Type: "select", Options: datatypes.JSON(`["EUR","USD"]`)},
+		},
+		Tags: []models.Tag{{Name: "finance"}},
+	}}
+	catalogs := []models.MessageCatalog{{
+		ID:            catalogID,
+		Name:          "billing",
+		DefaultLocale: "en",
+		Messages:      datatypes.JSON(`{"total":{"en":"Total","de":"Summe"}}`),
+	}}
+
+	b, err := New(templates, catalogs, now)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, b))
+
+	read, err := Read(buf.Bytes())
+	require.NoError(t, err)
+
+	require.Equal(t, now, read.CreatedAt.UTC())
+	require.Len(t, read.Catalogs, 1)
+	require.Equal(t, Catalog{
+		ID:            catalogID.String(),
+		Name:          "billing",
+		DefaultLocale: "en",
+		Messages:      map[string]map[string]string{"total": {"en": "Total", "de": "Summe"}},
+	}, read.Catalogs[0])
+
+	require.Len(t, read.Templates, 1)
+	template := read.Templates[0]
+	require.Equal(t, templateID.String(), template.ID)
+	require.Equal(t, "Invoice", template.Name)
+	require.Equal(t, catalogID.String(), template.CatalogID)
+	require.Equal(t, []string{"finance"}, template.Tags)
+	require.Equal(t, []string{"header"}, template.Partials)
+	require.Equal(t, []int{1, 2}, []int{template.Versions[0].Version, template.Versions[1].Version})
+	require.Equal(t, "<p>{{amount}}</p>", template.Versions[0].Content)
+	require.Equal(t, []string{"amount", "currency"}, []string{template.Fields[0].Key, template.Fields[1].Key})
+	require.JSONEq(t, `["EUR","USD"]`, string(template.Fields[1].Options))
+	require.Equal(t, map[string]any{"amount": float64(0), "currency": "EUR"}, template.Sample)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, manifestPath, zr.File[0].Name)
+}
+
+func TestReadInvalid(t *testing.T) {
+	valid := func() (manifest, map[string]string) {
+		return manifest{
+			Format:  Format,
+			Version: FormatVersion,
+			Templates: []manifestTemplate{{
+				ID:       "t1",
+				Name:     "Invoice",
+				Versions: []manifestVersion{{Version: 1, Path: "templates/t1/versions/1.hbs"}},
+				Fields:   "templates/t1/fields.json",
+			}},
+		}, map[string]string{
+			"templates/t1/versions/1.hbs": "<p>{{amount}}</p>",
+			"templates/t1/fields.json":    "[]",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(m *manifest, files map[string]string)
+		err    string
+	}{
+		{
+			name:   "valid",
+			modify: func(*manifest, map[string]string) {},
+		},
+		{
+			name:   "wrong format",
+			modify: func(m *manifest, _ map[string]string) { m.Format = "other" },
+			err:    "is not a template-bundle manifest",
+		},
+		{
+			name:   "newer version",
+			modify: func(m *manifest, _ map[string]string) { m.Version = FormatVersion + 1 },
+			err:    "unsupported bundle version",
+		},
+		{
+			name:   "missing content",
+			modify: func(_ *manifest, files map[string]string) { delete(files, "templates/t1/versions/1.hbs") },
+			err:    "version 1: opening",
+		},
+		{
+			name: "path outside the archive",
+			modify: func(m *manifest, _ map[string]string) {
+				m.Templates[0].Versions[0].Path = "../etc/passwd"
+			},
+			err: "opening",
+		},
+		{
+			name: "versions out of order",
+			modify: func(m *manifest, _ map[string]string) {
+				m.Templates[0].Versions = append(m.Templates[0].Versions, m.Templates[0].Versions[0])
+			},
+			err: "version 1 is out of order",
+		},
+		{
+			name:   "unknown catalog",
+			modify: func(m *manifest, _ map[string]string) { m.Templates[0].Catalog = "c1" },
+			err:    `catalog "c1" is not in the bundle`,
+		},
+		{
+			name:   "no versions",
+			modify: func(m *manifest, _ map[string]string) { m.Templates[0].Versions = nil },
+			err:    "no versions",
+		},
+		{
+			name: "shared content",
+			modify: func(m *manifest, _ map[string]string) {
+				m.Templates[0].Versions = append(m.Templates[0].Versions,
+					manifestVersion{Version: 2, Path: m.Templates[0].Versions[0].Path})
+			},
+			err: "templates/t1/versions/1.hbs is referenced more than once",
+		},
+		{
+			name: "too many templates",
+			modify: func(m *manifest, _ map[string]string) {
+				for i := range maxTemplates {
+					m.Templates = append(m.Templates, manifestTemplate{ID: fmt.Sprint(i)})
+				}
+			},
+			err: "more than 500 templates",
+		},
+		{
+			name: "too many versions",
+			modify: func(m *manifest, _ map[string]string) {
+				for i := range maxVersions {
+					m.Templates[0].Versions = append(m.Templates[0].Versions, manifestVersion{Version: i + 2})
+				}
+			},
+			err: "more than 5000 versions",
+		},
+		{
+			name: "too large uncompressed",
+			modify: func(m *manifest, files map[string]string) {
+				for i := range maxReadSize / maxFileSize {
+					path := fmt.Sprintf("templates/t1/versions/%d.hbs", i+2)
+					m.Templates[0].Versions = append(m.Templates[0].Versions, manifestVersion{Version: i + 2, Path: path})
+					files[path] = strings.Repeat("x", maxFileSize)
+				}
+			},
+			err: "bundle is larger than 33554432 bytes uncompressed",
+		},
+		{
+			name: "duplicate template",
+			modify: func(m *manifest, _ map[string]string) {
+				m.Templates = append(m.Templates, m.Templates[0])
+			},
+			err: "duplicate ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, files := valid()
+			tt.modify(&m, files)
+
+			_, err := Read(archive(t, m, files))
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := Read([]byte("not a zip"))
+	require.ErrorContains(t, err, "reading archive")
+}
+
+func archive(t *testing.T, m manifest, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	files[manifestPath] = string(data)
+
+	for path, content := range files {
+		f, err := zw.Create(path)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
@@ -7,8 +7,11 @@ package config
 import (
 	"errors"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/dashboard-platform/template-service/internal/encryption"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -20,6 +23,27 @@ type Config struct {
 	Port string // The port on which the server will run.
 	Env  string // The current environment (e.g., "dev", "prod").
 	DSN  string // The Data Source Name for connecting to the database.
+
+	TrustedGateway    bool     // Accept the X-User-ID header set by a trusted gateway.
+	JWTSecret         string   // Shared secret for HS256 bearer tokens.
+	JWTPublicKeyFiles []string // PEM files with RSA public keys for RS256 bearer tokens.
+	JWKSFile          string   // JWKS file with RSA public keys for RS256 bearer tokens.
+	JWTIssuer         string   // Required token issuer, if set.
+	JWTAudience       string   // Required token audience, if set.
+
+	ShareLinkSecret string // Signs share link tokens; share links are disabled if empty.
+
+	HistoryEncryptionKey []byte // AES-256 key for render values in the history and webhook secrets, if set.
+
+	HistoryRetentionDays int           // Global maximum history entry age in days; 0 keeps entries.
+	HistoryKeepLast      int           // Global number of entries kept per template; 0 keeps all.
+	HistoryPurgeInterval time.Duration // Interval between history purges; 0 disables purging.
+
+	IdempotencyTTL time.Duration // How long responses are replayed for an Idempotency-Key.
+
+	CleanupInterval time.Duration // Interval between cleanups of expired records; 0 disables them.
+
+	WebhookInterval time.Duration // Interval between webhook dispatcher runs; 0 disables delivery.
 }
 
 const (
@@ -27,7 +51,26 @@ const (
 	portEnv = "PORT" // Environment variable key for the server port.
 	dsnEnv  = "DSN"  // Database URL environment variable key.
 
-	defaultEnvKey = "dev" // Default environment name if none is provided.
+	trustedGatewayEnv    = "TRUSTED_GATEWAY"        // Enables X-User-ID header authentication.
+	jwtSecretEnv         = "JWT_SECRET"             // HS256 shared secret.
+	jwtPublicKeyFilesEnv = "JWT_PUBLIC_KEY_FILES"   // Comma-separated RS256 PEM key files.
+	jwksFileEnv          = "JWT_JWKS_FILE"          // RS256 JWKS key file.
+	jwtIssuerEnv         = "JWT_ISSUER"             // Expected token issuer.
+	jwtAudienceEnv       = "JWT_AUDIENCE"           // Expected token audience.
+	shareLinkSecretEnv   = "SHARE_LINK_SECRET"      // Share link signing key.
+	historyKeyEnv        = "HISTORY_ENCRYPTION_KEY" // Base64 encoded 32-byte history key.
+	retentionDaysEnv     = "HISTORY_RETENTION_DAYS" // Global history retention in days.
+	keepLastEnv          = "HISTORY_KEEP_LAST"      // Global history entries kept per template.
+	purgeIntervalEnv     = "HISTORY_PURGE_INTERVAL" // History purge interval, e.g. "1h".
+	idempotencyTTLEnv    = "IDEMPOTENCY_TTL"        // Idempotency key lifetime, e.g. "24h".
+	cleanupIntervalEnv   = "CLEANUP_INTERVAL"       // Expired record cleanup interval, e.g. "1h".
+	webhookIntervalEnv   = "WEBHOOK_INTERVAL"       // Webhook dispatcher interval, e.g. "5s".
+
+	defaultEnvKey          = "dev"           // Default environment name if none is provided.
+	defaultPurgeInterval   = time.Hour       // Default history purge interval.
+	defaultIdempotencyTTL  = 24 * time.Hour  // Default idempotency key lifetime.
+	defaultCleanupInterval = time.Hour       // Default expired record cleanup interval.
+	defaultWebhookInterval = 5 * time.Second // Default webhook dispatcher interval.
 )
 
 // Load retrieves the application configuration from environment variables.
@@ -55,9 +98,97 @@ func Load() (Config, error) {
 		return Config{}, errors.New("empty dsn")
 	}
 
+	if v := os.Getenv(trustedGatewayEnv); v != "" {
+		trusted, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, errors.New("invalid " + trustedGatewayEnv)
+		}
+		c.TrustedGateway = trusted
+	}
+
+	c.JWTSecret = os.Getenv(jwtSecretEnv)
+	c.JWTPublicKeyFiles = splitList(os.Getenv(jwtPublicKeyFilesEnv))
+	c.JWKSFile = os.Getenv(jwksFileEnv)
+	c.JWTIssuer = os.Getenv(jwtIssuerEnv)
+	c.JWTAudience = os.Getenv(jwtAudienceEnv)
+
+	c.ShareLinkSecret = os.Getenv(shareLinkSecretEnv)
+
+	if v := os.Getenv(historyKeyEnv); v != "" {
+		key, err := encryption.ParseKey(v)
+		if err != nil {
+			return Config{}, errors.New("invalid " + historyKeyEnv + ": " + err.Error())
+		}
+		c.HistoryEncryptionKey = key
+	}
+
+	for env, dst := range map[string]*int{
+		retentionDaysEnv: &c.HistoryRetentionDays,
+		keepLastEnv:      &c.HistoryKeepLast,
+	} {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return Config{}, errors.New("invalid " + env)
+			}
+			*dst = n
+		}
+	}
+
+	c.HistoryPurgeInterval = defaultPurgeInterval
+	if v := os.Getenv(purgeIntervalEnv); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval < 0 {
+			return Config{}, errors.New("invalid " + purgeIntervalEnv)
+		}
+		c.HistoryPurgeInterval = interval
+	}
+
+	c.IdempotencyTTL = defaultIdempotencyTTL
+	if v := os.Getenv(idempotencyTTLEnv); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return Config{}, errors.New("invalid " + idempotencyTTLEnv)
+		}
+		c.IdempotencyTTL = ttl
+	}
+
+	c.CleanupInterval = defaultCleanupInterval
+	if v := os.Getenv(cleanupIntervalEnv); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval < 0 {
+			return Config{}, errors.New("invalid " + cleanupIntervalEnv)
+		}
+		c.CleanupInterval = interval
+	}
+
+	c.WebhookInterval = defaultWebhookInterval
+	if v := os.Getenv(webhookIntervalEnv); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval < 0 {
+			return Config{}, errors.New("invalid " + webhookIntervalEnv)
+		}
+		c.WebhookInterval = interval
+	}
+
+	if !c.TrustedGateway && c.JWTSecret == "" && len(c.JWTPublicKeyFiles) == 0 && c.JWKSFile == "" {
+		return Config{}, errors.New("no JWT keys configured and trusted gateway mode is off")
+	}
+
 	return c, nil
 }
 
+// splitList splits a comma-separated environment value, dropping empty entries.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 // getEnv retrieves the value of an environment variable.
 // If the variable is not set, it logs an error and returns an empty string.
 //
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errAPIKeyNotFound is returned for API keys that do not exist or belong to another user.
+var errAPIKeyNotFound = notFound("api_key_not_found", "api key not found")
+
+// API key scopes limit what a key may be used for.
+const (
+	APIKeyScopeRead   = "read"   // Read templates and catalogs.
+	APIKeyScopeRender = "render" // Render template previews.
+)
+
+// apiKeyPrefix marks keys issued by this service.
+const apiKeyPrefix = "tsk_"
+
+// ErrInvalidAPIKey is returned for unknown, revoked or expired API keys.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// hashAPIKey returns the hex encoded SHA-256 digest stored for a key.
+// Keys carry 256 bits of randomness, so a fast hash is sufficient.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey issues an API key acting as the scope's user in the scope's workspace.
+//
+// Parameters:
+//   - scope: The caller and workspace the key is bound to.
+//   - input: The key name, scopes, template restrictions and expiry.
+//
+// Returns:
+//   - models.APIKey: The stored key.
+//   - string: The plaintext key. It is not stored and cannot be retrieved again.
+//   - error: An error if the input is invalid or the key cannot be stored.
+func (d *Database) CreateAPIKey(scope Scope, input models.CreateAPIKeyAPI) (models.APIKey, string, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return models.APIKey{}, "", invalid("name_missing", "api key name is missing")
+	}
+
+	if len(input.Scopes) == 0 {
+		return models.APIKey{}, "", invalid("scopes_missing", "api key scopes are missing")
+	}
+	for _, s := range input.Scopes {
+		if s != APIKeyScopeRead && s != APIKeyScopeRender {
+			return models.APIKey{}, "", invalid("invalid_scope", "invalid api key scope %q", s)
+		}
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return models.APIKey{}, "", invalid("invalid_expiry", "api key expiry is in the past")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.APIKey{}, "", err
+	}
+	plaintext := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := models.APIKey{
+		ID:          uuid.New(),
+		UserID:      scope.UserID,
+		OrgID:       scope.OrgID,
+		Name:        input.Name,
+		Prefix:      plaintext[:len(apiKeyPrefix)+6],
+		Hash:        hashAPIKey(plaintext),
+		Scopes:      slices.Compact(slices.Sorted(slices.Values(input.Scopes))),
+		TemplateIDs: input.TemplateIDs,
+		ExpiresAt:   input.ExpiresAt,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if scope.OrgID != nil {
+			if err := requireOrgRole(tx, scope, OrgRoleViewer); err != nil {
+				return err
+			}
+		}
+
+		// Every restricted template must be readable by the caller when the key is issued.
+		for _, id := range input.TemplateIDs {
+			if _, err := authorizeTemplate(tx, id, scope, RoleViewer); err != nil {
+				return fmt.Errorf("template %s: %w", id, err)
+			}
+		}
+
+		return tx.Create(&key).Error
+	})
+
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	return key, plaintext, nil
+}
+
+// GetAPIKeys returns the API keys the scope's user issued in the scope's workspace.
+func (d *Database) GetAPIKeys(scope Scope) ([]models.APIKey, error) {
+	var keys []models.APIKey
+
+	query := d.db.Where("user_id = ?", scope.UserID)
+	if scope.OrgID != nil {
+		query = query.Where("org_id = ?", *scope.OrgID)
+	} else {
+		query = query.Where("org_id IS NULL")
+	}
+
+	if err := query.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// DeleteAPIKey revokes one of the scope user's API keys.
+func (d *Database) DeleteAPIKey(scope Scope, idStr string) error {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return errAPIKeyNotFound
+	}
+
+	result := d.db.Where("id = ? AND user_id = ?", id, scope.UserID).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// VerifyAPIKey looks up a plaintext API key and records its use.
+//
+// Parameters:
+//   - plaintext: The key sent by the client.
+//
+// Returns:
+//   - models.APIKey: The matching key.
+//   - error: ErrInvalidAPIKey if the key is unknown, revoked or expired.
+func (d *Database) VerifyAPIKey(plaintext string) (models.APIKey, error) {
+	var key models.APIKey
+
+	if !strings.HasPrefix(plaintext, apiKeyPrefix) {
+		return key, ErrInvalidAPIKey
+	}
+
+	if err := d.db.Where("hash = ?", hashAPIKey(plaintext)).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return key, ErrInvalidAPIKey
+		}
+		return key, err
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return key, ErrInvalidAPIKey
+	}
+
+	// Writing on every request would be wasteful; minute precision is enough.
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > time.Minute {
+		if err := d.db.Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+			return key, err
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
@@ -0,0 +1,278 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/bundle"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportTemplates loads templates with every version, their fields and tags, and
+// the message catalogs they reference, for writing a bundle.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - templateIDStr: The template to export, or empty for every template owned by
+//     the scope's workspace.
+//
+// Returns:
+//   - []models.Template: The templates, by name.
+//   - []models.MessageCatalog: The catalogs referenced by the templates.
+//   - error: ErrTemplateNotFound, a role error or a database error.
+func (d *Database) ExportTemplates(scope Scope, templateIDStr string) ([]models.Template, []models.MessageCatalog, error) {
+	query := d.db.Preload("Fields").Preload("Versions").Preload("Tags").Order("name, id")
+
+	if templateIDStr != "" {
+		templateID, err := uuid.Parse(templateIDStr)
+		if err != nil {
+			return nil, nil, ErrTemplateNotFound
+		}
+		if _, err := authorizeTemplate(d.db, templateID, scope, RoleViewer); err != nil {
+			return nil, nil, err
+		}
+		query = query.Where("id = ?", templateID)
+	} else {
+		if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+			return nil, nil, err
+		}
+		query = workspaceOwned(query, scope)
+		if len(scope.TemplateIDs) > 0 {
+			query = query.Where("id IN ?", scope.TemplateIDs)
+		}
+	}
+
+	var templates []models.Template
+	if err := query.Find(&templates).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var catalogIDs []uuid.UUID
+	for _, t := range templates {
+		if t.CatalogID != nil {
+			catalogIDs = append(catalogIDs, *t.CatalogID)
+		}
+	}
+
+	var catalogs []models.MessageCatalog
+	if len(catalogIDs) > 0 {
+		if err := d.db.Where("id IN ?", catalogIDs).Order("name, id").Find(&catalogs).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return templates, catalogs, nil
+}
+
+// ImportTemplates creates the templates of a bundle in the scope's workspace, with
+// their versions, fields and tags. Catalogs are matched by name and created if the
+// workspace has none of that name. Either every template is imported or none is.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - b: The bundle, as returned by bundle.Read.
+//
+// Returns:
+//   - models.ImportResultDTO: The IDs the templates and catalogs were imported as.
+//   - error: A role, validation or database error.
+func (d *Database) ImportTemplates(scope Scope, b bundle.Bundle) (models.ImportResultDTO, error) {
+	result := models.ImportResultDTO{
+		Templates: []models.ImportedTemplateDTO{},
+		Catalogs:  []models.ImportedCatalogDTO{},
+	}
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		catalogIDs := make(map[string]uuid.UUID, len(b.Catalogs))
+		for _, c := range b.Catalogs {
+			catalog, created, err := importCatalog(tx, scope, c)
+			if err != nil {
+				return err
+			}
+			catalogIDs[c.ID] = catalog.ID
+			result.Catalogs = append(result.Catalogs, models.ImportedCatalogDTO{
+				SourceID: c.ID,
+				ID:       catalog.ID.String(),
+				Name:     catalog.Name,
+				Created:  created,
+			})
+		}
+
+		for _, t := range b.Templates {
+			template, err := importTemplate(tx, scope, t, catalogIDs)
+			if err != nil {
+				return err
+			}
+			result.Templates = append(result.Templates, models.ImportedTemplateDTO{
+				SourceID: t.ID,
+				ID:       template.ID.String(),
+				Name:     template.Name,
+				Versions: len(t.Versions),
+			})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return models.ImportResultDTO{}, err
+	}
+
+	return result, nil
+}
+
+// importCatalog returns the workspace's catalog named like c, or creates it.
+func importCatalog(tx *gorm.DB, scope Scope, c bundle.Catalog) (models.MessageCatalog, bool, error) {
+	var catalog models.MessageCatalog
+
+	err := workspaceOwned(tx, scope).Where("name = ?", c.Name).Order("created_at").First(&catalog).Error
+	if err == nil {
+		return catalog, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return catalog, false, err
+	}
+
+	input := models.CatalogAPI{Name: c.Name, DefaultLocale: c.DefaultLocale, Messages: c.Messages}
+	messages, err := catalogMessages(input)
+	if err != nil {
+		return catalog, false, err
+	}
+
+	catalog = models.MessageCatalog{
+		ID:            uuid.New(),
+		UserID:        scope.UserID,
+		OrgID:         scope.OrgID,
+		Name:          input.Name,
+		DefaultLocale: input.DefaultLocale,
+		Messages:      messages,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if catalog.DefaultLocale == "" {
+		catalog.DefaultLocale = defaultCatalogLocale
+	}
+
+	if err := tx.Create(&catalog).Error; err != nil {
+		return catalog, false, err
+	}
+
+	return catalog, true, nil
+}
+
+// importTemplate creates a template of a bundle with its versions, fields and tags.
+func importTemplate(tx *gorm.DB, scope Scope, t bundle.Template, catalogIDs map[string]uuid.UUID) (models.Template, error) {
+	if strings.TrimSpace(t.Name) == "" {
+		return models.Template{}, invalid("name_missing", "template %s has no name", t.ID)
+	}
+
+	seen := make(map[string]bool, len(t.Fields))
+	fields := make([]models.TemplateField, 0, len(t.Fields))
+	for _, f := range t.Fields {
+		input := models.TemplateFieldAPI{Key: f.Key, Label: f.Label, Type: f.Type, Required: f.Required, Options: f.Options}
+		if strings.TrimSpace(f.Key) == "" || strings.TrimSpace(f.Label) == "" {
+			return models.Template{}, invalid("field_invalid", "template %s: key and/or label are missing", t.ID)
+		}
+		if seen[f.Key] {
+			err := conflict(ErrDuplicateField.Code, "template %s: duplicate field key %q", t.ID, f.Key)
+			err.Details = map[string]any{"key": f.Key}
+			return models.Template{}, err
+		}
+		seen[f.Key] = true
+
+		options, err := fieldOptions(input)
+		if err != nil {
+			return models.Template{}, err
+		}
+
+		field := models.TemplateField{
+			ID:        uuid.New(),
+			Key:       f.Key,
+			Label:     f.Label,
+			Type:      f.Type,
+			Required:  f.Required,
+			Options:   options,
+			CreatedAt: time.Now(),
+		}
+		if field.Type == "" {
+			field.Type = "text" // default
+		}
+		fields = append(fields, field)
+	}
+
+	head := t.Versions[len(t.Versions)-1]
+	template := models.Template{
+		ID:            uuid.New(),
+		UserID:        scope.UserID,
+		OrgID:         scope.OrgID,
+		Name:          t.Name,
+		Description:   t.Description,
+		Type:          t.Type,
+		Category:      t.Category,
+		IsPublic:      false,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+		SearchContent: head.Content,
+	}
+	if t.CatalogID != "" {
+		catalogID := catalogIDs[t.CatalogID]
+		template.CatalogID = &catalogID
+	}
+
+	if err := tx.Create(&template).Error; err != nil {
+		return models.Template{}, err
+	}
+
+	for _, v := range t.Versions {
+		version := models.TemplateVersion{
+			ID:         uuid.New(),
+			TemplateID: template.ID,
+			Version:    v.Version,
+			Content:    v.Content,
+			CreatedAt:  v.CreatedAt,
+		}
+		if version.CreatedAt.IsZero() {
+			version.CreatedAt = time.Now()
+		}
+
+		if err := tx.Create(&version).Error; err != nil {
+			return models.Template{}, err
+		}
+	}
+
+	for _, field := range fields {
+		field.TemplateID = template.ID
+		if err := tx.Create(&field).Error; err != nil {
+			return models.Template{}, err
+		}
+	}
+
+	tags := make([]models.Tag, 0, len(t.Tags))
+	for _, name := range t.Tags {
+		tag, err := findOrCreateTag(tx, scope, name)
+		if err != nil {
+			return models.Template{}, err
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > 0 {
+		if err := tx.Model(&template).Association("Tags").Replace(tags); err != nil {
+			return models.Template{}, err
+		}
+	}
+
+	if err := enqueueEvent(tx, EventTemplateCreated, template, scope.UserID, models.WebhookEventDataDTO{}); err != nil {
+		return models.Template{}, err
+	}
+	if err := enqueueEvent(tx, EventVersionPublished, template, scope.UserID, models.WebhookEventDataDTO{Version: head.Version}); err != nil {
+		return models.Template{}, err
+	}
+
+	return template, nil
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/i18n"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errCatalogNotFound is returned for catalogs outside the scope's workspace.
+var errCatalogNotFound = notFound("catalog_not_found", "catalog not found in workspace")
+
+const defaultCatalogLocale = "en"
+
+// CreateCatalog stores a new message catalog in the scope's workspace.
+func (d *Database) CreateCatalog(scope Scope, input models.CatalogAPI) (models.MessageCatalog, error) {
+	messages, err := catalogMessages(input)
+	if err != nil {
+		return models.MessageCatalog{}, err
+	}
+
+	if err := requireOrgRole(d.db, scope, OrgRoleEditor); err != nil {
+		return models.MessageCatalog{}, err
+	}
+
+	catalog := models.MessageCatalog{
+		ID:            uuid.New(),
+		UserID:        scope.UserID,
+		OrgID:         scope.OrgID,
+		Name:          input.Name,
+		DefaultLocale: input.DefaultLocale,
+		Messages:      messages,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if catalog.DefaultLocale == "" {
+		catalog.DefaultLocale = defaultCatalogLocale
+	}
+
+	if err := d.db.Create(&catalog).Error; err != nil {
+		return models.MessageCatalog{}, err
+	}
+
+	return catalog, nil
+}
+
+// GetCatalogs returns every catalog of the scope's workspace. Scopes limited to some
+// templates see only the catalogs those templates use.
+func (d *Database) GetCatalogs(scope Scope) ([]models.MessageCatalog, error) {
+	var catalogs []models.MessageCatalog
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return nil, err
+	}
+
+	if err := workspaceOwned(referencedBy(d.db, scope, catalogIDs), scope).Order("name").Find(&catalogs).Error; err != nil {
+		return nil, err
+	}
+
+	return catalogs, nil
+}
+
+// GetCatalog returns a single catalog of the scope's workspace.
+func (d *Database) GetCatalog(scope Scope, catalogIDStr string) (models.MessageCatalog, error) {
+	catalogID, err := uuid.Parse(catalogIDStr)
+	if err != nil {
+		return models.MessageCatalog{}, errCatalogNotFound
+	}
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return models.MessageCatalog{}, err
+	}
+
+	return getCatalog(referencedBy(d.db, scope, catalogIDs), scope, catalogID)
+}
+
+// catalogIDs selects the catalogs of templates.
+func catalogIDs(templates *gorm.DB) *gorm.DB {
+	return templates.Select("templates.catalog_id")
+}
+
+// UpdateCatalog replaces the name, default locale and messages of a catalog.
+func (d *Database) UpdateCatalog(scope Scope, catalogIDStr string, input models.CatalogAPI) error {
+	catalogID, err := uuid.Parse(catalogIDStr)
+	if err != nil {
+		return errCatalogNotFound
+	}
+
+	messages, err := catalogMessages(input)
+	if err != nil {
+		return err
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		catalog, err := getCatalog(tx, scope, catalogID)
+		if err != nil {
+			return err
+		}
+
+		catalog.Name = input.Name
+		catalog.Messages = messages
+		if input.DefaultLocale != "" {
+			catalog.DefaultLocale = input.DefaultLocale
+		}
+
+		return tx.Save(&catalog).Error
+	})
+}
+
+// DeleteCatalog removes a catalog and detaches it from the templates that use it.
+func (d *Database) DeleteCatalog(scope Scope, catalogIDStr string) error {
+	catalogID, err := uuid.Parse(catalogIDStr)
+	if err != nil {
+		return errCatalogNotFound
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		catalog, err := getCatalog(tx, scope, catalogID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Template{}).
+			Where("catalog_id = ?", catalogID).
+			Update("catalog_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&catalog).Error
+	})
+}
+
+// RenderSnapshot resolves the translation state for rendering a template: its
+// catalog messages and the locale to render in. Templates without a catalog
+// yield a snapshot holding only the locale.
+func (d *Database) RenderSnapshot(template models.Template, locale string) (models.RenderSnapshot, error) {
+	snapshot := models.RenderSnapshot{Locale: locale}
+
+	if template.CatalogID == nil {
+		return snapshot, nil
+	}
+
+	catalog, err := getCatalog(d.db, ownerScope(template), *template.CatalogID)
+	if err != nil {
+		return snapshot, err
+	}
+
+	if err := json.Unmarshal(catalog.Messages, &snapshot.Messages); err != nil {
+		return snapshot, err
+	}
+
+	snapshot.DefaultLocale = catalog.DefaultLocale
+	if snapshot.Locale == "" {
+		snapshot.Locale = catalog.DefaultLocale
+	}
+
+	return snapshot, nil
+}
+
+// getCatalog loads a catalog from the scope's workspace. Membership is not checked.
+func getCatalog(tx *gorm.DB, scope Scope, catalogID uuid.UUID) (models.MessageCatalog, error) {
+	var catalog models.MessageCatalog
+
+	if err := workspaceOwned(tx, scope).Where("id = ?", catalogID).First(&catalog).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return catalog, errCatalogNotFound
+		}
+		return catalog, err
+	}
+
+	return catalog, nil
+}
+
+// catalogMessages validates the input and encodes its messages for storage.
+func catalogMessages(input models.CatalogAPI) ([]byte, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, invalid("name_missing", "catalog name is missing")
+	}
+
+	if input.Messages == nil {
+		input.Messages = map[string]map[string]string{}
+	}
+
+	if err := i18n.ValidateMessages(input.Messages); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(input.Messages)
+}
@@ -0,0 +1,283 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/diff"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errSourceNotFound is returned for clone sources the caller cannot read.
+var errSourceNotFound = notFound("template_not_found", "source template not found or not accessible")
+
+// CloneTemplate copies the head version and fields of a template readable in the
+// scope, or of a public template, into a new template in the scope's workspace.
+func (d *Database) CloneTemplate(scope Scope, sourceIDStr string, input models.CloneTemplateAPI) (models.Template, error) {
+	sourceID, err := uuid.Parse(sourceIDStr)
+	if err != nil {
+		return models.Template{}, errSourceNotFound
+	}
+
+	var clone models.Template
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		source, err := readableSource(tx, scope, sourceID)
+		if err != nil {
+			return err
+		}
+
+		if len(source.Versions) == 0 {
+			return conflict("no_versions", "source template has no versions")
+		}
+		head := source.Versions[0]
+
+		name := strings.TrimSpace(input.Name)
+		if name == "" {
+			name = source.Name
+		}
+
+		clone = models.Template{
+			ID:               uuid.New(),
+			UserID:           scope.UserID,
+			OrgID:            scope.OrgID,
+			Name:             name,
+			Description:      source.Description,
+			Type:             source.Type,
+			Category:         source.Category,
+			IsPublic:         false,
+			SourceTemplateID: &source.ID,
+			SourceVersion:    head.Version,
+			SearchContent:    head.Content,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
+		}
+
+		// Catalogs cannot be referenced across workspaces.
+		if sameWorkspace(source.OrgID, scope) && (scope.OrgID != nil || source.UserID == scope.UserID) {
+			clone.CatalogID = source.CatalogID
+		}
+
+		if err := tx.Create(&clone).Error; err != nil {
+			return err
+		}
+
+		version := models.TemplateVersion{
+			ID:         uuid.New(),
+			TemplateID: clone.ID,
+			Version:    1,
+			Content:    head.Content,
+			CreatedAt:  time.Now(),
+		}
+
+		if err := tx.Create(&version).Error; err != nil {
+			return err
+		}
+		clone.Versions = []models.TemplateVersion{version}
+
+		fields, err := copyFields(tx, clone.ID, source.Fields)
+		if err != nil {
+			return err
+		}
+		clone.Fields = fields
+
+		if err := enqueueEvent(tx, EventTemplateCreated, clone, scope.UserID, models.WebhookEventDataDTO{}); err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventVersionPublished, clone, scope.UserID, models.WebhookEventDataDTO{Version: 1})
+	})
+
+	if err != nil {
+		return models.Template{}, err
+	}
+
+	return clone, nil
+}
+
+// GetUpstreamDiff compares a cloned template's head version with the current head
+// of its source template. Versions too large or too different to compare are
+// reported as unprocessable.
+func (d *Database) GetUpstreamDiff(scope Scope, templateIDStr string) (models.UpstreamDTO, error) {
+	fork, err := d.GetTemplateByID(scope, templateIDStr)
+	if err != nil {
+		return models.UpstreamDTO{}, err
+	}
+
+	if fork.SourceTemplateID == nil {
+		return models.UpstreamDTO{}, conflict("not_a_clone", "template is not a clone")
+	}
+
+	source, err := readableSource(d.db, scope, *fork.SourceTemplateID)
+	if err != nil {
+		return models.UpstreamDTO{}, err
+	}
+
+	if len(fork.Versions) == 0 || len(source.Versions) == 0 {
+		return models.UpstreamDTO{}, conflict("no_versions", "template has no versions")
+	}
+
+	forkHead, upstreamHead := fork.Versions[0], source.Versions[0]
+
+	unified, err := diff.Unified(
+		fmt.Sprintf("%s@v%d", fork.ID, forkHead.Version),
+		fmt.Sprintf("%s@v%d", source.ID, upstreamHead.Version),
+		forkHead.Content,
+		upstreamHead.Content,
+		3,
+	)
+	if errors.Is(err, diff.ErrTooLarge) {
+		return models.UpstreamDTO{}, unprocessable("diff_too_large",
+			"template and upstream versions are too large or too different to compare")
+	}
+	if err != nil {
+		return models.UpstreamDTO{}, err
+	}
+
+	return models.UpstreamDTO{
+		SourceTemplateID: source.ID.String(),
+		BaseVersion:      fork.SourceVersion,
+		UpstreamVersion:  upstreamHead.Version,
+		UpToDate:         fork.SourceVersion >= upstreamHead.Version,
+		Diff:             unified,
+	}, nil
+}
+
+// PullUpstream stores the source template's head content as a new version of the
+// clone and replaces the clone's fields with the source fields.
+func (d *Database) PullUpstream(scope Scope, templateIDStr string) (models.TemplateVersion, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return models.TemplateVersion{}, ErrTemplateNotFound
+	}
+
+	var version models.TemplateVersion
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		fork, err := authorizeTemplate(tx, templateID, scope, RoleEditor)
+		if err != nil {
+			return err
+		}
+
+		if fork.SourceTemplateID == nil {
+			return conflict("not_a_clone", "template is not a clone")
+		}
+
+		source, err := readableSource(tx, scope, *fork.SourceTemplateID)
+		if err != nil {
+			return err
+		}
+		if len(source.Versions) == 0 {
+			return conflict("no_versions", "source template has no versions")
+		}
+		head := source.Versions[0]
+
+		next, err := nextVersion(tx, fork.ID)
+		if err != nil {
+			return err
+		}
+
+		version = models.TemplateVersion{
+			ID:         uuid.New(),
+			TemplateID: fork.ID,
+			Version:    next,
+			Content:    head.Content,
+			CreatedAt:  time.Now(),
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("template_id = ?", fork.ID).Delete(&models.TemplateField{}).Error; err != nil {
+			return err
+		}
+		if _, err := copyFields(tx, fork.ID, source.Fields); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&fork).Updates(map[string]any{
+			"source_version": head.Version,
+			"search_content": head.Content,
+			"updated_at":     time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		return enqueueEvent(tx, EventVersionPublished, fork, scope.UserID, models.WebhookEventDataDTO{Version: version.Version})
+	})
+
+	if err != nil {
+		return models.TemplateVersion{}, err
+	}
+
+	return version, nil
+}
+
+// readableSource loads a template that is public or readable by the caller in the
+// scope, with its fields and versions (head first).
+func readableSource(tx *gorm.DB, scope Scope, templateID uuid.UUID) (models.Template, error) {
+	var source models.Template
+
+	if err := tx.
+		Preload("Fields").
+		Preload("Versions", latestVersionFirst).
+		Where("id = ?", templateID).
+		First(&source).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return source, errSourceNotFound
+		}
+		return source, err
+	}
+
+	if source.IsPublic {
+		return source, nil
+	}
+
+	if _, err := authorizeTemplate(tx, templateID, scope, RoleViewer); err != nil {
+		return source, errSourceNotFound
+	}
+
+	return source, nil
+}
+
+// copyFields duplicates field definitions onto another template.
+func copyFields(tx *gorm.DB, templateID uuid.UUID, fields []models.TemplateField) ([]models.TemplateField, error) {
+	copies := make([]models.TemplateField, 0, len(fields))
+	for _, f := range fields {
+		field := models.TemplateField{
+			ID:         uuid.New(),
+			TemplateID: templateID,
+			Key:        f.Key,
+			Label:      f.Label,
+			Type:       f.Type,
+			Required:   f.Required,
+			Options:    f.Options,
+			CreatedAt:  time.Now(),
+		}
+
+		if err := tx.Create(&field).Error; err != nil {
+			return nil, err
+		}
+		copies = append(copies, field)
+	}
+
+	return copies, nil
+}
+
+// nextVersion returns the version number following the template's head version.
+func nextVersion(tx *gorm.DB, templateID uuid.UUID) (int, error) {
+	var head int
+	if err := tx.Unscoped().Model(&models.TemplateVersion{}).
+		Where("template_id = ?", templateID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&head).Error; err != nil {
+		return 0, err
+	}
+
+	return head + 1, nil
+}
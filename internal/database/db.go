@@ -6,15 +6,17 @@
 package database
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/dashboard-platform/template-service/internal/encryption"
+	"github.com/dashboard-platform/template-service/internal/jsonschema"
 	"github.com/dashboard-platform/template-service/models"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -23,11 +25,22 @@ import (
 	gormLog "gorm.io/gorm/logger"
 )
 
+// errHistoryNotFound is returned for history entries the caller cannot read.
+var errHistoryNotFound = notFound("history_not_found", "history entry not found")
+
 // Database represents the database connection and provides methods for interacting with it.
 // It includes a GORM database instance and a logger for logging database operations.
 type Database struct {
-	db     *gorm.DB       // GORM database instance.
-	logger zerolog.Logger // Logger for database operations.
+	db     *gorm.DB           // GORM database instance.
+	logger zerolog.Logger     // Logger for database operations.
+	cipher *encryption.Cipher // Seals webhook secrets at rest, if set.
+}
+
+// SetCipher sets the cipher that seals webhook signing secrets at rest. It must be
+// called before AutoMigrate, which seals the secrets stored in plaintext. Without a
+// cipher, secrets are stored in plaintext.
+func (d *Database) SetCipher(c *encryption.Cipher) {
+	d.cipher = c
 }
 
 // Init initializes a new database connection using the provided DSN (Data Source Name).
@@ -49,7 +62,8 @@ func Init(dsn string, logger zerolog.Logger) (*Database, error) {
 	maxRetries := 10
 	for i := 0; i < maxRetries; i++ {
 		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-			Logger: gormLog.Default.LogMode(gormLog.Silent),
+			Logger:         gormLog.Default.LogMode(gormLog.Silent),
+			TranslateError: true,
 		})
 
 		if err == nil {
@@ -84,6 +98,15 @@ func (d *Database) AutoMigrate() error {
 		return err
 	}
 
+	if err := d.db.AutoMigrate(&models.Tag{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.Folder{}); err != nil {
+		return err
+	}
+
+	// Tags must exist before the template_tags join table.
 	if err := d.db.AutoMigrate(&models.Template{}); err != nil {
 		return err
 	}
@@ -92,28 +115,98 @@ func (d *Database) AutoMigrate() error {
 		return err
 	}
 
+	if err := d.db.AutoMigrate(&models.MessageCatalog{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.TemplateShare{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.Organization{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.OrgMember{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.APIKey{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.Favorite{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.RetentionPolicy{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.IdempotencyKey{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.Webhook{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.WebhookEvent{}); err != nil {
+		return err
+	}
+
+	if err := d.db.AutoMigrate(&models.WebhookDelivery{}); err != nil {
+		return err
+	}
+
+	if err := d.sealWebhookSecrets(); err != nil {
+		return err
+	}
+
 	d.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_template_version_unique ON template_versions (template_id, version);")
 	d.db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_template_field_key ON template_fields (template_id, key);")
+	d.db.Exec("CREATE INDEX IF NOT EXISTS idx_templates_updated_at_id ON templates (updated_at, id);")
+	d.db.Exec("CREATE INDEX IF NOT EXISTS idx_templates_name_id ON templates (name, id);")
+	d.db.Exec("CREATE INDEX IF NOT EXISTS idx_template_histories_user_used ON template_histories (user_id, used_at DESC, id DESC);")
+	d.db.Exec("CREATE INDEX IF NOT EXISTS idx_template_histories_template_used ON template_histories (template_id, used_at DESC, id DESC);")
+
+	if err := migrateTags(d.db); err != nil {
+		return err
+	}
 
-	return nil
+	return migrateSearch(d.db)
 }
 
-func (d *Database) CreateTemplate(userID uuid.UUID, input models.CreateTemplateAPI) (uuid.UUID, error) {
+func (d *Database) CreateTemplate(scope Scope, input models.CreateTemplateAPI) (uuid.UUID, error) {
 	var templateID uuid.UUID
 	err := d.db.Transaction(func(tx *gorm.DB) error {
 		templateID = uuid.New()
 
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		if input.CatalogID != nil {
+			if _, err := getCatalog(tx, scope, *input.CatalogID); err != nil {
+				return err
+			}
+		}
+
 		// Create Template
 		template := models.Template{
 			ID:          templateID,
-			UserID:      userID,
+			UserID:      scope.UserID,
+			OrgID:       scope.OrgID,
 			Name:        input.Name,
 			Description: input.Description,
 			Type:        input.Type,
 			Category:    input.Category,
 			IsPublic:    false,
+			CatalogID:   input.CatalogID,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
+
+			SearchContent: input.Content,
 		}
 
 		if err := tx.Create(&template).Error; err != nil {
@@ -148,18 +241,21 @@ func (d *Database) CreateTemplate(userID uuid.UUID, input models.CreateTemplateA
 				field.Type = "text" // default
 			}
 
-			if f.Options != nil {
-				var jsonCheck interface{}
-				if err := json.Unmarshal(f.Options, &jsonCheck); err != nil {
-					return err
-				}
+			options, err := fieldOptions(f)
+			if err != nil {
+				return err
 			}
+			field.Options = options
 
 			if err := tx.Create(&field).Error; err != nil {
 				return err
 			}
 		}
-		return nil
+
+		if err := enqueueEvent(tx, EventTemplateCreated, template, scope.UserID, models.WebhookEventDataDTO{}); err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventVersionPublished, template, scope.UserID, models.WebhookEventDataDTO{Version: 1})
 	})
 
 	if err != nil {
@@ -169,42 +265,23 @@ func (d *Database) CreateTemplate(userID uuid.UUID, input models.CreateTemplateA
 	return templateID, nil
 }
 
-func (d *Database) GetTemplates(userIDStr string) ([]models.Template, error) {
-	var templates []models.Template
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := d.db.
-		Preload("Fields").
-		Preload("Versions").
-		Where("user_id = ?", userID).
-		Find(&templates).Error; err != nil {
-		return nil, err
-	}
-
-	return templates, nil
-}
-
-func (d *Database) GetTemplateByID(userIDStr, templateIDStr string) (models.Template, error) {
+func (d *Database) GetTemplateByID(scope Scope, templateIDStr string) (models.Template, error) {
 	var template models.Template
 
 	templateID, err := uuid.Parse(templateIDStr)
 	if err != nil {
-		return template, err
+		return template, ErrTemplateNotFound
 	}
 
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
+	if _, err := authorizeTemplate(d.db, templateID, scope, RoleViewer); err != nil {
 		return template, err
 	}
 
 	if err := d.db.
 		Preload("Fields").
-		Preload("Versions").
-		Where("id = ? AND user_id = ?", templateID, userID).
+		Preload("Versions", latestVersionFirst).
+		Preload("Tags").
+		Where("id = ?", templateID).
 		First(&template).Error; err != nil {
 		return template, err
 	}
@@ -212,85 +289,234 @@ func (d *Database) GetTemplateByID(userIDStr, templateIDStr string) (models.Temp
 	return template, nil
 }
 
-func (d *Database) UpdateTemplate(userIDStr, templateIDStr string, input models.CreateTemplateAPI) error {
+// UpdateTemplate replaces a template. Content that differs from the latest
+// version is stored as a new version.
+//
+// Parameters:
+//   - scope: The caller's workspace.
+//   - templateIDStr: The template ID.
+//   - input: The new name, description, type, category, catalog, content and fields.
+//   - ifMatch: An If-Match header the template must match, or empty.
+//
+// Returns:
+//   - string: The entity tag of the updated template.
+//   - error: ErrTemplateNotFound, ErrInsufficientRole, ErrPreconditionFailed,
+//     ErrDuplicateField or a validation or database error.
+func (d *Database) UpdateTemplate(scope Scope, templateIDStr string, input models.CreateTemplateAPI, ifMatch string) (string, error) {
 	templateID, err := uuid.Parse(templateIDStr)
 	if err != nil {
+		return "", ErrTemplateNotFound
+	}
+
+	var etag string
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		template, err := authorizeTemplate(tx, templateID, scope, RoleEditor)
+		if err != nil {
+			return err
+		}
+
+		if err := checkIfMatch(tx, template, ifMatch); err != nil {
+			return err
+		}
+
+		// A replacement is complete; omitting these would erase them.
+		if strings.TrimSpace(input.Type) == "" {
+			return invalid("type_missing", "template type is missing")
+		}
+		if input.Content == "" {
+			return invalid("content_missing", "template content is missing")
+		}
+
+		etag, err = updateTemplate(tx, template, input, scope.UserID)
 		return err
+	})
+
+	return etag, err
+}
+
+// updateTemplate replaces an authorized template on behalf of actorID and returns
+// its new entity tag. Content differing from the latest version is published as a
+// new version.
+func updateTemplate(tx *gorm.DB, template models.Template, input models.CreateTemplateAPI, actorID uuid.UUID) (string, error) {
+	options, err := checkUpdate(input)
+	if err != nil {
+		return "", err
 	}
 
-	userID, err := uuid.Parse(userIDStr)
+	head, err := latestVersion(tx, template.ID)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if err := d.db.Unscoped().Where("template_id = ?", templateID).Delete(&models.TemplateField{}).Error; err != nil {
-		return err
+	if err := tx.Unscoped().Where("template_id = ?", template.ID).Delete(&models.TemplateField{}).Error; err != nil {
+		return "", err
 	}
 
-	return d.db.Transaction(func(tx *gorm.DB) error {
-		var template models.Template
+	if input.CatalogID != nil {
+		// Catalogs belong to the template's workspace, not to the editor.
+		if _, err := getCatalog(tx, ownerScope(template), *input.CatalogID); err != nil {
+			return "", err
+		}
+	}
 
-		if err := tx.
-			Where("id = ? AND user_id = ?", templateID, userID).
-			First(&template).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("template not found or not owned by user")
-			}
-			return err
+	template.Name = input.Name
+	template.Description = input.Description
+	template.Type = input.Type
+	template.Category = input.Category
+	template.CatalogID = input.CatalogID
+	template.UpdatedAt = time.Now().Truncate(time.Microsecond)
+	columns := []string{"Name", "Description", "Type", "Category", "CatalogID", "UpdatedAt"}
+
+	version := head.Version
+	published := input.Content != head.Content
+	if published {
+		if version, err = nextVersion(tx, template.ID); err != nil {
+			return "", err
+		}
+		if err := tx.Create(&models.TemplateVersion{
+			ID:         uuid.New(),
+			TemplateID: template.ID,
+			Version:    version,
+			Content:    input.Content,
+			CreatedAt:  time.Now(),
+		}).Error; err != nil {
+			return "", err
 		}
 
-		template.Name = input.Name
-		template.Description = input.Description
+		template.SearchContent = input.Content
+		columns = append(columns, "SearchContent")
+	}
 
-		for _, f := range input.Fields {
-			if strings.TrimSpace(f.Key) == "" || strings.TrimSpace(f.Label) == "" {
-				return fmt.Errorf("key and/or label are missing")
-			}
+	for i, f := range input.Fields {
+		field := models.TemplateField{
+			ID:         uuid.New(),
+			TemplateID: template.ID,
+			Key:        f.Key,
+			Label:      f.Label,
+			Type:       f.Type,
+			Required:   f.Required,
+			Options:    options[i],
+			CreatedAt:  time.Now(),
+		}
 
-			field := models.TemplateField{
-				ID:         uuid.New(),
-				TemplateID: templateID,
-				Key:        f.Key,
-				Label:      f.Label,
-				Type:       f.Type,
-				Required:   f.Required,
-				CreatedAt:  time.Now(),
-			}
+		if field.Type == "" {
+			field.Type = "text" // default
+		}
 
-			if field.Type == "" {
-				field.Type = "text" // default
-			}
+		if err := tx.Create(&field).Error; err != nil {
+			return "", err
+		}
+	}
 
-			if err := tx.Create(&field).Error; err != nil {
-				return err
-			}
+	if err := tx.Model(&template).
+		Select(columns).
+		Updates(&template).Error; err != nil {
+		return "", err
+	}
+
+	if err := enqueueEvent(tx, EventTemplateUpdated, template, actorID, models.WebhookEventDataDTO{Version: version}); err != nil {
+		return "", err
+	}
+	if published {
+		if err := enqueueEvent(tx, EventVersionPublished, template, actorID, models.WebhookEventDataDTO{Version: version}); err != nil {
+			return "", err
+		}
+	}
+
+	return templateETag(template.ID, template.UpdatedAt, version), nil
+}
+
+// latestVersion returns the latest version of a template, or a zero version if it
+// has none.
+func latestVersion(tx *gorm.DB, templateID uuid.UUID) (models.TemplateVersion, error) {
+	var head models.TemplateVersion
+	err := tx.Where("template_id = ?", templateID).
+		Scopes(latestVersionFirst).
+		Limit(1).
+		Find(&head).Error
+	return head, err
+}
+
+// checkUpdate validates the name and fields of an update and returns the options
+// of each field for storage.
+func checkUpdate(input models.CreateTemplateAPI) ([]datatypes.JSON, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, invalid("name_missing", "template name is missing")
+	}
+
+	seen := make(map[string]bool, len(input.Fields))
+	options := make([]datatypes.JSON, len(input.Fields))
+	for i, f := range input.Fields {
+		if strings.TrimSpace(f.Key) == "" || strings.TrimSpace(f.Label) == "" {
+			err := invalid("field_invalid", "field %q: key and/or label are missing", f.Key)
+			err.Details = map[string]any{"key": f.Key}
+			return nil, err
+		}
+		if seen[f.Key] {
+			err := conflict(ErrDuplicateField.Code, "duplicate field key %q", f.Key)
+			err.Details = map[string]any{"key": f.Key}
+			return nil, err
 		}
+		seen[f.Key] = true
 
-		if err := tx.Updates(&template).Error; err != nil {
-			return err
+		var err error
+		if options[i], err = fieldOptions(f); err != nil {
+			return nil, err
 		}
+	}
 
-		return nil
+	return options, nil
+}
+
+// fieldOptions checks the options of a field against its type and returns them
+// for storage, or nil if the field has none.
+func fieldOptions(f models.TemplateFieldAPI) (datatypes.JSON, error) {
+	_, err := jsonschema.Field(models.FieldDTO{
+		Key:      f.Key,
+		Label:    f.Label,
+		Type:     f.Type,
+		Required: f.Required,
+		Options:  f.Options,
 	})
+	if err != nil {
+		e := invalid("invalid_field_options", "field %q: %v", f.Key, err)
+		e.Details = map[string]any{"key": f.Key}
+		return nil, e
+	}
+
+	if len(f.Options) == 0 || string(f.Options) == "null" {
+		return nil, nil
+	}
+	return datatypes.JSON(f.Options), nil
 }
 
-func (d *Database) DeleteTemplate(userIDStr, templateIDStr string) error {
+// DeleteTemplate deletes a template with its versions, fields, shares, tags and
+// favorites. If ifMatch is set, the template must match it.
+func (d *Database) DeleteTemplate(scope Scope, templateIDStr string, ifMatch string) error {
 	return d.db.Transaction(func(tx *gorm.DB) error {
 		templateID, err := uuid.Parse(templateIDStr)
 		if err != nil {
-			return err
+			return ErrTemplateNotFound
 		}
 
-		userID, err := uuid.Parse(userIDStr)
+		template, err := authorizeTemplate(tx, templateID, scope, RoleOwner)
 		if err != nil {
 			return err
 		}
 
-		var template models.Template
-		if err := tx.Where("id = ? AND user_id = ?", templateID, userID).First(&template).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return fmt.Errorf("template not found or not owned by user")
-			}
+		if err := checkIfMatch(tx, template, ifMatch); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("template_id = ?", templateID).Delete(&models.TemplateShare{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&template).Association("Tags").Clear(); err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("template_id = ?", templateID).Delete(&models.Favorite{}).Error; err != nil {
 			return err
 		}
 
@@ -306,34 +532,63 @@ func (d *Database) DeleteTemplate(userIDStr, templateIDStr string) error {
 			return err
 		}
 
-		return nil
+		return enqueueEvent(tx, EventTemplateDeleted, template, scope.UserID, models.WebhookEventDataDTO{})
 	})
 }
 
-func (d *Database) CreateHistory(template models.TemplateDTO, userID uuid.UUID) error {
-	templateID, err := uuid.Parse(template.ID)
-	if err != nil {
-		return err
+// CreateHistory stores a render of template in the history and records the
+// template.rendered event.
+func (d *Database) CreateHistory(template models.Template, entry models.TemplateHistory) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
 	}
 
-	data := models.TemplateHistory{
-		ID:           uuid.New(),
-		UserID:       userID,
-		TemplateID:   templateID,
-		TemplateName: template.Name,
-		Version:      template.Version.Version,
-	}
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
 
-	return d.db.Create(&data).Error
+		return enqueueEvent(tx, EventTemplateRendered, template, entry.UserID, models.WebhookEventDataDTO{
+			Version:   entry.Version,
+			HistoryID: entry.ID.String(),
+		})
+	})
 }
 
-func (d *Database) GetHistory(userID uuid.UUID) ([]models.TemplateHistory, error) {
-	var history []models.TemplateHistory
+// GetHistoryEntry loads a history entry with the exact template version it
+// rendered. Callers must still be able to read the template: they may read their
+// own renders and, as template owners, every render of their templates. Entries
+// of templates the caller lost access to, or that were deleted, are not found.
+func (d *Database) GetHistoryEntry(scope Scope, historyIDStr string) (models.TemplateHistory, models.TemplateVersion, error) {
+	var entry models.TemplateHistory
+	var version models.TemplateVersion
 
-	err := d.db.Where("user_id = ?", userID).Find(&history).Error
+	historyID, err := uuid.Parse(historyIDStr)
 	if err != nil {
-		return nil, err
+		return entry, version, errHistoryNotFound
+	}
+
+	if err := d.db.Where("id = ?", historyID).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entry, version, errHistoryNotFound
+		}
+		return entry, version, err
+	}
+
+	// Reading the values other users rendered with is reserved to owners.
+	required := RoleViewer
+	if entry.UserID != scope.UserID {
+		required = RoleOwner
+	}
+	if _, err := authorizeTemplate(d.db, entry.TemplateID, scope, required); err != nil {
+		return entry, version, errHistoryNotFound
+	}
+
+	if err := d.db.Unscoped().
+		Where("template_id = ? AND version = ?", entry.TemplateID, entry.Version).
+		First(&version).Error; err != nil {
+		return entry, version, fmt.Errorf("template version %d: %w", entry.Version, err)
 	}
 
-	return history, nil
+	return entry, version, nil
 }
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"os"
+	"testing"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLog "gorm.io/gorm/logger"
+)
+
+// testDSNEnv names the PostgreSQL database the integration tests run against.
+const testDSNEnv = "TEST_DSN"
+
+// testDatabase connects to the database in TEST_DSN and migrates it. Tests using
+// it are skipped when TEST_DSN is not set.
+func testDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skip(testDSNEnv + " is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger:         gormLog.Default.LogMode(gormLog.Silent),
+		TranslateError: true,
+	})
+	require.NoError(t, err)
+
+	d := &Database{db: db, logger: zerolog.Nop()}
+	require.NoError(t, d.AutoMigrate())
+	return d
+}
+
+// testTemplate creates a personal template of a new user and returns the user's
+// scope and the template ID.
+func testTemplate(t *testing.T, d *Database) (Scope, string) {
+	t.Helper()
+
+	scope := Scope{UserID: uuid.New()}
+	id, err := d.CreateTemplate(scope, testTemplateInput())
+	require.NoError(t, err)
+
+	return scope, id.String()
+}
+
+// testTemplateInput describes a template with one field.
+func testTemplateInput() models.CreateTemplateAPI {
+	return models.CreateTemplateAPI{
+		Name:    "Invoice",
+		Type:    "html",
+		Content: "<p>{{name}}</p>",
+		Fields:  []models.TemplateFieldAPI{{Key: "name", Label: "Name"}},
+	}
+}
+
+// TestUpdateTemplateReplaces verifies that PUT replaces the type, category and
+// content, publishing changed content as a new version.
+func TestUpdateTemplateReplaces(t *testing.T) {
+	d := testDatabase(t)
+	scope, id := testTemplate(t, d)
+
+	replacement := models.CreateTemplateAPI{
+		Name:     "Receipt",
+		Type:     "text",
+		Category: "billing",
+		Content:  "Paid by {{name}}",
+		Fields:   []models.TemplateFieldAPI{{Key: "name", Label: "Name"}},
+	}
+
+	for range 2 {
+		_, err := d.UpdateTemplate(scope, id, replacement, "")
+		require.NoError(t, err)
+
+		template, err := d.GetTemplateByID(scope, id)
+		require.NoError(t, err)
+		require.Equal(t, "text", template.Type)
+		require.Equal(t, "billing", template.Category)
+		// Replacing with the same content again adds no version.
+		require.Len(t, template.Versions, 2)
+		require.Equal(t, replacement.Content, template.Versions[0].Content)
+	}
+
+	replacement.Content = ""
+	_, err := d.UpdateTemplate(scope, id, replacement, "")
+	require.ErrorIs(t, err, invalid("content_missing", ""))
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrorKind classifies domain errors so that callers can react without matching
+// messages.
+type ErrorKind int
+
+const (
+	KindNotFound      ErrorKind = iota + 1 // The resource does not exist or is not visible.
+	KindForbidden                          // The caller may not perform the operation.
+	KindConflict                           // The operation conflicts with the current state.
+	KindValidation                         // The input is invalid.
+	KindPrecondition                       // A request precondition does not hold.
+	KindUnprocessable                      // The input is well-formed but cannot be applied.
+)
+
+// Error is a domain error with a machine-readable code. Its message is safe to
+// show to clients.
+type Error struct {
+	Kind    ErrorKind
+	Code    string         // Stable identifier, e.g. "template_not_found".
+	Message string         // Human-readable description.
+	Details map[string]any // Optional structured context.
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors of the same kind and code, so that errors.Is works with the
+// exported sentinels even when the message carries specifics.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+func newError(kind ErrorKind, code, format string, args ...any) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(code, format string, args ...any) *Error {
+	return newError(KindNotFound, code, format, args...)
+}
+
+func forbidden(code, format string, args ...any) *Error {
+	return newError(KindForbidden, code, format, args...)
+}
+
+func conflict(code, format string, args ...any) *Error {
+	return newError(KindConflict, code, format, args...)
+}
+
+func invalid(code, format string, args ...any) *Error {
+	return newError(KindValidation, code, format, args...)
+}
+
+func unprocessable(code, format string, args ...any) *Error {
+	return newError(KindUnprocessable, code, format, args...)
+}
+
+// AsError returns the domain error in err's chain. Record-not-found errors and
+// constraint violations reported by lower layers are translated to domain errors
+// as well.
+//
+// Parameters:
+//   - err: The error to inspect.
+//
+// Returns:
+//   - *Error: The domain error.
+//   - bool: false if err is not a domain error.
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return notFound("not_found", "resource not found"), true
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return conflict("already_exists", "resource already exists"), true
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return conflict("reference_violation", "resource is referenced or references a missing resource"), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemplateETag verifies that tags change with the update time and head version
+// and ignore sub-microsecond differences.
+func TestTemplateETag(t *testing.T) {
+	id := uuid.New()
+	at := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+
+	tag := templateETag(id, at, 3)
+	require.Equal(t, tag, templateETag(id, at.Add(999*time.Nanosecond), 3))
+	require.NotEqual(t, tag, templateETag(id, at.Add(time.Microsecond), 3))
+	require.NotEqual(t, tag, templateETag(id, at, 4))
+
+	template := models.Template{
+		ID:        id,
+		UpdatedAt: at,
+		Versions:  []models.TemplateVersion{{Version: 3}, {Version: 2}},
+	}
+	require.Equal(t, tag, TemplateETag(template))
+}
+
+// TestMatchETag verifies strong and weak comparison of precondition headers.
+func TestMatchETag(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		weak   bool
+		want   bool
+	}{
+		{name: "equal", header: `"abc"`, want: true},
+		{name: "list", header: `"xyz", "abc"`, want: true},
+		{name: "wildcard", header: "*", want: true},
+		{name: "different", header: `"xyz"`, want: false},
+		{name: "weak strong comparison", header: `W/"abc"`, want: false},
+		{name: "weak weak comparison", header: `W/"abc"`, weak: true, want: true},
+		{name: "unquoted", header: `abc`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, MatchETag(tt.header, `"abc"`, tt.weak))
+		})
+	}
+}
+
+// TestIfMatchConcurrentWriters verifies that of two writers sending the same
+// If-Match tag at once, only one succeeds.
+func TestIfMatchConcurrentWriters(t *testing.T) {
+	d := testDatabase(t)
+	scope, id := testTemplate(t, d)
+
+	template, err := d.GetTemplateByID(scope, id)
+	require.NoError(t, err)
+	etag := TemplateETag(template)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = d.UpdateTemplate(scope, id, models.CreateTemplateAPI{
+				Name:   fmt.Sprintf("Writer %d", i),
+				Fields: []models.TemplateFieldAPI{{Key: "name", Label: "Name"}},
+			}, etag)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrPreconditionFailed)
+	}
+	require.Equal(t, 1, succeeded)
+}
+
+// TestTemplateETagTracksTagsAndFolder verifies that changing the tags or folder of a
+// template changes its entity tag.
+func TestTemplateETagTracksTagsAndFolder(t *testing.T) {
+	d := testDatabase(t)
+	scope, id := testTemplate(t, d)
+
+	etag := func() string {
+		template, err := d.GetTemplateByID(scope, id)
+		require.NoError(t, err)
+		return TemplateETag(template)
+	}
+
+	before := etag()
+	_, err := d.SetTemplateTags(scope, id, []string{"billing"})
+	require.NoError(t, err)
+	tagged := etag()
+	require.NotEqual(t, before, tagged)
+
+	folder, err := d.CreateFolder(scope, models.FolderAPI{Name: "Invoices"})
+	require.NoError(t, err)
+	require.NoError(t, d.MoveTemplate(scope, id, &folder.ID))
+	require.NotEqual(t, tagged, etag())
+}
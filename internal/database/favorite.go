@@ -0,0 +1,118 @@
+package database
+
+import (
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultRecentLimit = 10
+	maxRecentLimit     = 50
+	defaultRecentDays  = 90
+)
+
+// SetFavorite stars or unstars a template the caller can read.
+func (d *Database) SetFavorite(scope Scope, templateIDStr string, favorite bool) error {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return ErrTemplateNotFound
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := authorizeTemplate(tx, templateID, scope, RoleViewer); err != nil {
+			return err
+		}
+
+		if !favorite {
+			return tx.Unscoped().
+				Where("user_id = ? AND template_id = ?", scope.UserID, templateID).
+				Delete(&models.Favorite{}).Error
+		}
+
+		// Starring twice keeps the original favorite.
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Favorite{
+			ID:         uuid.New(),
+			UserID:     scope.UserID,
+			TemplateID: templateID,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}).Error
+	})
+}
+
+// GetFavorites returns the caller's starred templates in the scope's workspace,
+// most recently starred first.
+func (d *Database) GetFavorites(scope Scope) ([]models.TemplateSummaryDTO, error) {
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return nil, err
+	}
+
+	favorites := make([]models.TemplateSummaryDTO, 0)
+	if err := workspaceTemplates(d.db.Model(&models.Template{}), scope).
+		Select(summaryColumns).
+		Joins("JOIN favorites ON favorites.template_id = templates.id AND favorites.user_id = ?", scope.UserID).
+		Order("favorites.created_at DESC").
+		Scan(&favorites).Error; err != nil {
+		return nil, err
+	}
+
+	return favorites, nil
+}
+
+// GetRecentTemplates aggregates the caller's history over the last days into the
+// most recently and the most frequently used templates of the scope's workspace.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - days: The history window in days, defaulting to defaultRecentDays.
+//   - limit: The size of each list, capped at maxRecentLimit.
+//
+// Returns:
+//   - []models.TemplateUsageDTO: The templates used most recently.
+//   - []models.TemplateUsageDTO: The templates used most often.
+//   - error: An error if the query fails.
+func (d *Database) GetRecentTemplates(scope Scope, days, limit int) ([]models.TemplateUsageDTO, []models.TemplateUsageDTO, error) {
+	if days < 1 {
+		days = defaultRecentDays
+	}
+	if limit < 1 {
+		limit = defaultRecentLimit
+	}
+	if limit > maxRecentLimit {
+		limit = maxRecentLimit
+	}
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return nil, nil, err
+	}
+
+	usage := d.db.Model(&models.TemplateHistory{}).
+		Select("template_id, MAX(used_at) AS last_used_at, COUNT(*) AS use_count").
+		Where("user_id = ? AND used_at >= ?", scope.UserID, time.Now().AddDate(0, 0, -days)).
+		Group("template_id")
+
+	query := workspaceTemplates(d.db.Model(&models.Template{}), scope).
+		Select(summaryColumns+`, u.last_used_at, u.use_count,
+			EXISTS (SELECT 1 FROM favorites f
+				WHERE f.template_id = templates.id AND f.user_id = ? AND f.deleted_at IS NULL) AS is_favorite`,
+			scope.UserID).
+		Joins("JOIN (?) AS u ON u.template_id = templates.id", usage).
+		Limit(limit).
+		Session(&gorm.Session{})
+
+	recent := make([]models.TemplateUsageDTO, 0)
+	if err := query.Order("u.last_used_at DESC").Scan(&recent).Error; err != nil {
+		return nil, nil, err
+	}
+
+	frequent := make([]models.TemplateUsageDTO, 0)
+	if err := query.Order("u.use_count DESC, u.last_used_at DESC").Scan(&frequent).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return recent, frequent, nil
+}
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errFolderNotFound is returned for folders outside the scope's workspace.
+var errFolderNotFound = notFound("folder_not_found", "folder not found")
+
+// folderSubtree selects the IDs of a folder and all of its descendants.
+const folderSubtree = `WITH RECURSIVE subtree AS (
+		SELECT id FROM folders WHERE id = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT f.id FROM folders f JOIN subtree ON f.parent_id = subtree.id
+		WHERE f.deleted_at IS NULL
+	)
+	SELECT id FROM subtree`
+
+// CreateFolder adds a folder to the scope's workspace.
+func (d *Database) CreateFolder(scope Scope, input models.FolderAPI) (models.Folder, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return models.Folder{}, invalid("name_missing", "folder name is missing")
+	}
+
+	folder := models.Folder{
+		ID:        uuid.New(),
+		UserID:    scope.UserID,
+		OrgID:     scope.OrgID,
+		ParentID:  input.ParentID,
+		Name:      strings.TrimSpace(input.Name),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		if input.ParentID != nil {
+			if _, err := getFolder(tx, scope, *input.ParentID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&folder).Error
+	})
+
+	if err != nil {
+		return models.Folder{}, err
+	}
+
+	return folder, nil
+}
+
+// GetFolders returns every folder of the scope's workspace. Clients build the tree
+// from the parent IDs. Scopes limited to some templates see only the folders
+// holding those templates.
+func (d *Database) GetFolders(scope Scope) ([]models.Folder, error) {
+	var folders []models.Folder
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return nil, err
+	}
+
+	if err := workspaceOwned(referencedBy(d.db, scope, folderIDs), scope).Order("name").Find(&folders).Error; err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// UpdateFolder renames a folder and moves it under a new parent.
+func (d *Database) UpdateFolder(scope Scope, folderIDStr string, input models.FolderAPI) error {
+	folderID, err := uuid.Parse(folderIDStr)
+	if err != nil {
+		return errFolderNotFound
+	}
+
+	if strings.TrimSpace(input.Name) == "" {
+		return invalid("name_missing", "folder name is missing")
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		folder, err := getFolder(tx, scope, folderID)
+		if err != nil {
+			return err
+		}
+
+		if input.ParentID != nil {
+			if _, err := getFolder(tx, scope, *input.ParentID); err != nil {
+				return err
+			}
+
+			// A folder cannot move into itself or one of its descendants.
+			var cycles int64
+			if err := tx.Raw("SELECT COUNT(*) FROM ("+folderSubtree+") AS s WHERE s.id = ?",
+				folderID, *input.ParentID).Scan(&cycles).Error; err != nil {
+				return err
+			}
+			if cycles > 0 {
+				return invalid("folder_cycle", "cannot move a folder into itself")
+			}
+		}
+
+		return tx.Model(&folder).Updates(map[string]any{
+			"name":       strings.TrimSpace(input.Name),
+			"parent_id":  input.ParentID,
+			"updated_at": time.Now(),
+		}).Error
+	})
+}
+
+// DeleteFolder removes an empty folder.
+func (d *Database) DeleteFolder(scope Scope, folderIDStr string) error {
+	folderID, err := uuid.Parse(folderIDStr)
+	if err != nil {
+		return errFolderNotFound
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		folder, err := getFolder(tx, scope, folderID)
+		if err != nil {
+			return err
+		}
+
+		var children int64
+		if err := tx.Model(&models.Folder{}).Where("parent_id = ?", folderID).Count(&children).Error; err != nil {
+			return err
+		}
+
+		var templates int64
+		if err := tx.Model(&models.Template{}).Where("folder_id = ?", folderID).Count(&templates).Error; err != nil {
+			return err
+		}
+
+		if children > 0 || templates > 0 {
+			return conflict("folder_not_empty", "folder is not empty")
+		}
+
+		return tx.Delete(&folder).Error
+	})
+}
+
+// MoveTemplate places a template in a folder of its workspace, or at the root.
+func (d *Database) MoveTemplate(scope Scope, templateIDStr string, folderID *uuid.UUID) error {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return ErrTemplateNotFound
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		template, err := authorizeTemplate(tx, templateID, scope, RoleEditor)
+		if err != nil {
+			return err
+		}
+
+		if folderID != nil {
+			// Folders belong to the template's workspace, not to the editor.
+			if _, err := getFolder(tx, ownerScope(template), *folderID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&template).Updates(map[string]any{
+			"folder_id":  folderID,
+			"updated_at": time.Now().Truncate(time.Microsecond),
+		}).Error
+	})
+}
+
+// folderIDs selects the folders of templates.
+func folderIDs(templates *gorm.DB) *gorm.DB {
+	return templates.Select("templates.folder_id")
+}
+
+// getFolder loads a folder of the scope's workspace.
+func getFolder(tx *gorm.DB, scope Scope, folderID uuid.UUID) (models.Folder, error) {
+	var folder models.Folder
+
+	if err := workspaceOwned(tx, scope).Where("id = ?", folderID).First(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return folder, errFolderNotFound
+		}
+		return folder, err
+	}
+
+	return folder, nil
+}
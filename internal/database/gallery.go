@@ -0,0 +1,121 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errNotInGallery is returned for templates that are not published.
+var errNotInGallery = notFound("template_not_found", "template not found or not public")
+
+const (
+	defaultGalleryLimit = 20
+	maxGalleryLimit     = 100
+)
+
+// GalleryFilter narrows and paginates the public template gallery.
+type GalleryFilter struct {
+	Category string // Exact category match, ignored when empty.
+	Type     string // Exact type match (html, latex, ...), ignored when empty.
+	Page     int    // 1-based page number.
+	Limit    int    // Page size, capped at maxGalleryLimit.
+}
+
+// Normalize applies default and maximum page sizes.
+func (f *GalleryFilter) Normalize() {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Limit < 1 {
+		f.Limit = defaultGalleryLimit
+	}
+	if f.Limit > maxGalleryLimit {
+		f.Limit = maxGalleryLimit
+	}
+}
+
+// SetTemplateVisibility publishes or unpublishes a template the caller owns.
+func (d *Database) SetTemplateVisibility(scope Scope, templateIDStr string, public bool) error {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return ErrTemplateNotFound
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		template, err := authorizeTemplate(tx, templateID, scope, RoleOwner)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&template).Update("is_public", public).Error
+	})
+}
+
+// GetPublicTemplates lists public templates across all users.
+//
+// Returns:
+//   - []models.Template: The requested page of templates.
+//   - int64: The total number of templates matching the filter.
+//   - error: An error if the query fails.
+func (d *Database) GetPublicTemplates(filter GalleryFilter) ([]models.Template, int64, error) {
+	filter.Normalize()
+
+	query := d.db.Model(&models.Template{}).Where("is_public = ?", true)
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	// Let the count and the page query each start from the filtered statement.
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var templates []models.Template
+	if err := query.
+		Preload("Fields").
+		Preload("Versions", latestVersionFirst).
+		Order("updated_at DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&templates).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return templates, total, nil
+}
+
+// GetPublicTemplateByID returns a public template regardless of its owner.
+func (d *Database) GetPublicTemplateByID(templateIDStr string) (models.Template, error) {
+	var template models.Template
+
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return template, errNotInGallery
+	}
+
+	if err := d.db.
+		Preload("Fields").
+		Preload("Versions", latestVersionFirst).
+		Where("id = ? AND is_public = ?", templateID, true).
+		First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return template, errNotInGallery
+		}
+		return template, err
+	}
+
+	return template, nil
+}
+
+// latestVersionFirst orders preloaded versions so that Versions[0] is the head version.
+func latestVersionFirst(db *gorm.DB) *gorm.DB {
+	return db.Order("version DESC")
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
+// HistoryFilter narrows and paginates history queries. Entries are returned most
+// recent first.
+type HistoryFilter struct {
+	TemplateID *uuid.UUID // Entries of one template, ignored when nil.
+	Version    int        // Entries of one template version, ignored when 0.
+	From       *time.Time // Inclusive lower bound on used_at.
+	To         *time.Time // Exclusive upper bound on used_at.
+	Cursor     string     // Opaque position returned by the previous page.
+	Limit      int        // Page size, capped at maxHistoryLimit.
+}
+
+// Normalize applies default and maximum page sizes.
+func (f *HistoryFilter) Normalize() {
+	if f.Limit < 1 {
+		f.Limit = defaultHistoryLimit
+	}
+	if f.Limit > maxHistoryLimit {
+		f.Limit = maxHistoryLimit
+	}
+}
+
+// historyCursor is the position of the last entry of a history page.
+type historyCursor struct {
+	UsedAt time.Time `json:"t"`
+	ID     uuid.UUID `json:"id"`
+}
+
+func encodeHistoryCursor(entry models.TemplateHistory) string {
+	raw, _ := json.Marshal(historyCursor{UsedAt: entry.UsedAt, ID: entry.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeHistoryCursor(s string) (historyCursor, error) {
+	var c historyCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, invalid("invalid_cursor", "invalid cursor")
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == uuid.Nil {
+		return c, invalid("invalid_cursor", "invalid cursor")
+	}
+
+	return c, nil
+}
+
+// GetHistory returns one page of the caller's own renders.
+//
+// Parameters:
+//   - scope: The caller.
+//   - filter: The filters and page position.
+//
+// Returns:
+//   - []models.TemplateHistory: The requested page, most recent first.
+//   - string: The cursor of the next page, or empty on the last page.
+//   - error: An error if the cursor is invalid or the query fails.
+func (d *Database) GetHistory(scope Scope, filter HistoryFilter) ([]models.TemplateHistory, string, error) {
+	return historyPage(d.db.Where("user_id = ?", scope.UserID), filter)
+}
+
+// GetTemplateHistory returns one page of the renders of a template by any client.
+// Only the template's owners may read it, as entries hold other users' inputs.
+func (d *Database) GetTemplateHistory(scope Scope, templateIDStr string, filter HistoryFilter) ([]models.TemplateHistory, string, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return nil, "", ErrTemplateNotFound
+	}
+
+	if _, err := authorizeTemplate(d.db, templateID, scope, RoleOwner); err != nil {
+		return nil, "", err
+	}
+
+	filter.TemplateID = &templateID
+	return historyPage(d.db, filter)
+}
+
+func historyPage(query *gorm.DB, filter HistoryFilter) ([]models.TemplateHistory, string, error) {
+	filter.Normalize()
+
+	if filter.TemplateID != nil {
+		query = query.Where("template_id = ?", *filter.TemplateID)
+	}
+	if filter.Version > 0 {
+		query = query.Where("version = ?", filter.Version)
+	}
+	if filter.From != nil {
+		query = query.Where("used_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("used_at < ?", *filter.To)
+	}
+
+	if filter.Cursor != "" {
+		c, err := decodeHistoryCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(used_at, id) < (?, ?)", c.UsedAt, c.ID)
+	}
+
+	history := make([]models.TemplateHistory, 0)
+	if err := query.
+		Order("used_at DESC, id DESC").
+		Limit(filter.Limit + 1).
+		Find(&history).Error; err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(history) > filter.Limit {
+		history = history[:filter.Limit]
+		next = encodeHistoryCursor(history[len(history)-1])
+	}
+
+	return history, next, nil
+}
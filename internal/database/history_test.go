@@ -0,0 +1,41 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetHistoryEntryRequiresAccess verifies that callers lose access to their own
+// history entries when they lose access to the template.
+func TestGetHistoryEntryRequiresAccess(t *testing.T) {
+	d := testDatabase(t)
+	owner, id := testTemplate(t, d)
+
+	template, err := d.GetTemplateByID(owner, id)
+	require.NoError(t, err)
+
+	viewer := Scope{UserID: uuid.New()}
+	_, err = d.GrantShare(owner, id, models.ShareAPI{UserID: viewer.UserID, Role: string(RoleViewer)})
+	require.NoError(t, err)
+
+	entry := models.TemplateHistory{
+		ID:           uuid.New(),
+		UserID:       viewer.UserID,
+		TemplateID:   template.ID,
+		Version:      1,
+		TemplateName: template.Name,
+	}
+	require.NoError(t, d.CreateHistory(template, entry))
+
+	_, _, err = d.GetHistoryEntry(viewer, entry.ID.String())
+	require.NoError(t, err)
+	_, _, err = d.GetHistoryEntry(owner, entry.ID.String())
+	require.NoError(t, err)
+
+	require.NoError(t, d.RevokeShare(owner, id, viewer.UserID.String()))
+	_, _, err = d.GetHistoryEntry(viewer, entry.ID.String())
+	require.ErrorIs(t, err, errHistoryNotFound)
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// ReserveIdempotencyKey claims an idempotency key for a request. If the key is
+// already held, the existing record is returned instead; expired records are
+// replaced.
+//
+// Parameters:
+//   - owner: The caller the key belongs to.
+//   - key: The Idempotency-Key header value.
+//   - requestHash: A fingerprint of the request.
+//   - ttl: How long the outcome is kept.
+//
+// Returns:
+//   - models.IdempotencyKey: The new or existing record.
+//   - bool: true if the key was claimed by this request.
+//   - error: An error if the record cannot be stored or read.
+func (d *Database) ReserveIdempotencyKey(owner, key, requestHash string, ttl time.Duration) (models.IdempotencyKey, bool, error) {
+	now := time.Now()
+
+	// An expired record no longer blocks its key.
+	if err := d.db.Unscoped().
+		Where("owner = ? AND key = ? AND expires_at <= ?", owner, key, now).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return models.IdempotencyKey{}, false, err
+	}
+
+	record := models.IdempotencyKey{
+		ID:          uuid.New(),
+		Owner:       owner,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	result := d.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return models.IdempotencyKey{}, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := d.db.Where("owner = ? AND key = ?", owner, key).First(&existing).Error; err != nil {
+		return models.IdempotencyKey{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request holding a key.
+func (d *Database) CompleteIdempotencyKey(id uuid.UUID, status int, contentType string, body []byte) error {
+	return d.db.Model(&models.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       status,
+			"content_type": contentType,
+			"body":         body,
+			"updated_at":   time.Now(),
+		}).Error
+}
+
+// ReleaseIdempotencyKey frees a key whose request failed, so that it can be retried.
+func (d *Database) ReleaseIdempotencyKey(id uuid.UUID) error {
+	return d.db.Unscoped().Where("id = ?", id).Delete(&models.IdempotencyKey{}).Error
+}
+
+// PurgeIdempotencyKeys deletes expired idempotency records.
+//
+// Returns:
+//   - int64: The number of records deleted.
+//   - error: An error if the deletion fails.
+func (d *Database) PurgeIdempotencyKeys() (int64, error) {
+	result := d.db.Unscoped().Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+
+	defaultListSort = "-updated_at"
+)
+
+// summaryColumns selects the fields of models.TemplateSummaryDTO.
+const summaryColumns = `templates.id, templates.name, templates.description, templates.type,
+	templates.category, templates.org_id, templates.is_public, templates.folder_id,
+	templates.created_at, templates.updated_at,
+	(SELECT MAX(v.version) FROM template_versions v
+		WHERE v.template_id = templates.id AND v.deleted_at IS NULL) AS latest_version`
+
+// sortColumns maps the sort keys accepted by ListTemplates to columns.
+var sortColumns = map[string]string{
+	"name":       "templates.name",
+	"updated_at": "templates.updated_at",
+}
+
+// TemplateFilter narrows, orders and paginates a template listing.
+type TemplateFilter struct {
+	Type          string     // Exact type match, ignored when empty.
+	Tag           string     // Tag name the templates must carry, ignored when empty.
+	FolderID      *uuid.UUID // Folder holding the templates, directly or in a subfolder.
+	Category      string     // Exact category match, ignored when empty.
+	IsPublic      *bool      // Visibility match, ignored when nil.
+	CreatedAfter  *time.Time // Inclusive lower bound on created_at.
+	CreatedBefore *time.Time // Exclusive upper bound on created_at.
+	UpdatedAfter  *time.Time // Inclusive lower bound on updated_at.
+	UpdatedBefore *time.Time // Exclusive upper bound on updated_at.
+	Sort          string     // name or updated_at, prefixed with "-" for descending.
+	Cursor        string     // Opaque position returned by the previous page.
+	Limit         int        // Page size, capped at maxListLimit.
+}
+
+// Normalize applies the default sort and page sizes and validates the sort key.
+func (f *TemplateFilter) Normalize() error {
+	if f.Sort == "" {
+		f.Sort = defaultListSort
+	}
+	if _, ok := sortColumns[strings.TrimPrefix(f.Sort, "-")]; !ok {
+		return invalid("invalid_sort", "invalid sort %q", f.Sort)
+	}
+
+	if f.Limit < 1 {
+		f.Limit = defaultListLimit
+	}
+	if f.Limit > maxListLimit {
+		f.Limit = maxListLimit
+	}
+
+	return nil
+}
+
+// listCursor is the decoded form of a page cursor: the sort key and values of the
+// last row of the previous page. Name is set for name sorts, UpdatedAt otherwise.
+type listCursor struct {
+	Sort      string     `json:"s"`
+	Name      string     `json:"n,omitempty"`
+	UpdatedAt *time.Time `json:"u,omitempty"`
+	ID        uuid.UUID  `json:"id"`
+}
+
+func encodeCursor(c listCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s, sort string) (listCursor, error) {
+	var c listCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, invalid("invalid_cursor", "invalid cursor")
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, invalid("invalid_cursor", "invalid cursor")
+	}
+
+	if c.Sort != sort {
+		return c, invalid("invalid_cursor", "cursor does not match sort %q", sort)
+	}
+	if strings.TrimPrefix(sort, "-") == "updated_at" && c.UpdatedAt == nil {
+		return c, invalid("invalid_cursor", "invalid cursor")
+	}
+
+	return c, nil
+}
+
+// cursorAfter returns the cursor that continues a listing after item.
+func cursorAfter(sort string, item models.TemplateSummaryDTO) string {
+	c := listCursor{Sort: sort, ID: item.ID}
+	if strings.TrimPrefix(sort, "-") == "name" {
+		c.Name = item.Name
+	} else {
+		updatedAt := item.UpdatedAt
+		c.UpdatedAt = &updatedAt
+	}
+	return encodeCursor(c)
+}
+
+// ListTemplates returns one page of the templates visible in the scope, without
+// their content or fields.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - filter: The filters, sort order and page position.
+//
+// Returns:
+//   - []models.TemplateSummaryDTO: The requested page.
+//   - string: The cursor of the next page, or empty on the last page.
+//   - error: An error if the filter is invalid or the query fails.
+func (d *Database) ListTemplates(scope Scope, filter TemplateFilter) ([]models.TemplateSummaryDTO, string, error) {
+	if err := filter.Normalize(); err != nil {
+		return nil, "", err
+	}
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return nil, "", err
+	}
+
+	query := workspaceTemplates(d.db.Model(&models.Template{}), scope)
+
+	if filter.Type != "" {
+		query = query.Where("templates.type = ?", filter.Type)
+	}
+	if filter.Category != "" {
+		query = query.Where("templates.category = ?", filter.Category)
+	}
+	if filter.Tag != "" {
+		query = query.Where(`templates.id IN (
+			SELECT template_tags.template_id FROM template_tags
+			JOIN tags ON tags.id = template_tags.tag_id
+			WHERE tags.name = ? AND tags.deleted_at IS NULL)`, filter.Tag)
+	}
+	if filter.FolderID != nil {
+		query = query.Where("templates.folder_id IN ("+folderSubtree+")", *filter.FolderID)
+	}
+	if filter.IsPublic != nil {
+		query = query.Where("templates.is_public = ?", *filter.IsPublic)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("templates.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("templates.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		query = query.Where("templates.updated_at >= ?", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		query = query.Where("templates.updated_at < ?", *filter.UpdatedBefore)
+	}
+
+	column := sortColumns[strings.TrimPrefix(filter.Sort, "-")]
+	direction, op := "ASC", ">"
+	if strings.HasPrefix(filter.Sort, "-") {
+		direction, op = "DESC", "<"
+	}
+
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor, filter.Sort)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var value any = c.Name
+		if c.UpdatedAt != nil {
+			value = *c.UpdatedAt
+		}
+		query = query.Where(fmt.Sprintf("(%s, templates.id) %s (?, ?)", column, op), value, c.ID)
+	}
+
+	items := make([]models.TemplateSummaryDTO, 0)
+	if err := query.
+		Select(summaryColumns).
+		Order(fmt.Sprintf("%s %s, templates.id %s", column, direction, direction)).
+		Limit(filter.Limit + 1).
+		Scan(&items).Error; err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(items) > filter.Limit {
+		items = items[:filter.Limit]
+		next = cursorAfter(filter.Sort, items[len(items)-1])
+	}
+
+	return items, next, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemplateFilterNormalize verifies defaults, limits and sort validation.
+func TestTemplateFilterNormalize(t *testing.T) {
+	f := TemplateFilter{Limit: 1000}
+	require.NoError(t, f.Normalize())
+	require.Equal(t, defaultListSort, f.Sort)
+	require.Equal(t, maxListLimit, f.Limit)
+
+	f = TemplateFilter{Sort: "-name"}
+	require.NoError(t, f.Normalize())
+	require.Equal(t, defaultListLimit, f.Limit)
+
+	f = TemplateFilter{Sort: "content"}
+	require.Error(t, f.Normalize())
+}
+
+// TestListCursor verifies that cursors round-trip and are bound to their sort.
+func TestListCursor(t *testing.T) {
+	item := models.TemplateSummaryDTO{
+		ID:        uuid.New(),
+		Name:      "Invoice",
+		UpdatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC),
+	}
+
+	tests := []struct {
+		sort string
+	}{
+		{sort: "name"},
+		{sort: "-name"},
+		{sort: "updated_at"},
+		{sort: "-updated_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			c, err := decodeCursor(cursorAfter(tt.sort, item), tt.sort)
+			require.NoError(t, err)
+			require.Equal(t, item.ID, c.ID)
+
+			if c.UpdatedAt != nil {
+				require.True(t, item.UpdatedAt.Equal(*c.UpdatedAt))
+			} else {
+				require.Equal(t, item.Name, c.Name)
+			}
+		})
+	}
+
+	_, err := decodeCursor(cursorAfter("name", item), "-updated_at")
+	require.Error(t, err)
+
+	_, err = decodeCursor("%%%", "name")
+	require.Error(t, err)
+}
@@ -0,0 +1,380 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scope identifies the caller and the workspace a request operates in.
+// A nil OrgID selects the caller's personal workspace. A non-empty TemplateIDs
+// restricts the request to those templates, as for template-bound API keys.
+type Scope struct {
+	UserID      uuid.UUID
+	OrgID       *uuid.UUID
+	TemplateIDs []uuid.UUID
+}
+
+// OrgRole is a user's membership level in an organization.
+type OrgRole string
+
+const (
+	OrgRoleOwner  OrgRole = "owner"  // Manages the organization and its members.
+	OrgRoleAdmin  OrgRole = "admin"  // Manages members and owns every template.
+	OrgRoleEditor OrgRole = "editor" // Creates and edits templates.
+	OrgRoleViewer OrgRole = "viewer" // Reads and renders templates.
+)
+
+var errNotOrgMember = notFound("organization_not_found", "organization not found or user is not a member")
+
+func (r OrgRole) rank() int {
+	switch r {
+	case OrgRoleViewer:
+		return 1
+	case OrgRoleEditor:
+		return 2
+	case OrgRoleAdmin:
+		return 3
+	case OrgRoleOwner:
+		return 4
+	}
+	return 0
+}
+
+// Allows reports whether r grants at least the permissions of required.
+func (r OrgRole) Allows(required OrgRole) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// templateRole maps an organization role to the role it implies on the
+// organization's templates.
+func (r OrgRole) templateRole() Role {
+	switch r {
+	case OrgRoleOwner, OrgRoleAdmin:
+		return RoleOwner
+	case OrgRoleEditor:
+		return RoleEditor
+	case OrgRoleViewer:
+		return RoleViewer
+	}
+	return ""
+}
+
+// ParseOrgRole validates an organization role name.
+func ParseOrgRole(s string) (OrgRole, error) {
+	r := OrgRole(s)
+	if r.rank() == 0 {
+		return "", invalid("invalid_role", "invalid organization role %q", s)
+	}
+	return r, nil
+}
+
+// memberRole returns the user's role in an organization, or errNotOrgMember.
+func memberRole(tx *gorm.DB, orgID, userID uuid.UUID) (OrgRole, error) {
+	var member models.OrgMember
+
+	if err := tx.Where("org_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errNotOrgMember
+		}
+		return "", err
+	}
+
+	return OrgRole(member.Role), nil
+}
+
+// requireOrgRole checks that the scope's user holds at least required in the
+// scope's organization. Personal scopes always pass.
+func requireOrgRole(tx *gorm.DB, scope Scope, required OrgRole) error {
+	if scope.OrgID == nil {
+		return nil
+	}
+
+	role, err := memberRole(tx, *scope.OrgID, scope.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !role.Allows(required) {
+		return forbidden("insufficient_role", "organization role %s required", required)
+	}
+
+	return nil
+}
+
+// sameWorkspace reports whether a resource's organization matches the scope's.
+func sameWorkspace(orgID *uuid.UUID, scope Scope) bool {
+	if orgID == nil || scope.OrgID == nil {
+		return orgID == nil && scope.OrgID == nil
+	}
+	return *orgID == *scope.OrgID
+}
+
+// ownerScope returns the scope of the workspace a template belongs to.
+func ownerScope(template models.Template) Scope {
+	return Scope{UserID: template.UserID, OrgID: template.OrgID}
+}
+
+// workspaceTemplates restricts a template query to those visible in the scope:
+// every template of the organization, or the user's personal and shared templates.
+// The caller must have verified organization membership.
+func workspaceTemplates(tx *gorm.DB, scope Scope) *gorm.DB {
+	if len(scope.TemplateIDs) > 0 {
+		tx = tx.Where("templates.id IN ?", scope.TemplateIDs)
+	}
+
+	if scope.OrgID != nil {
+		return tx.Where("templates.org_id = ?", *scope.OrgID)
+	}
+
+	return tx.Where("templates.org_id IS NULL AND (templates.user_id = ? OR templates.id IN (?))",
+		scope.UserID, sharedTemplateIDs(tx, scope.UserID))
+}
+
+// workspaceOwned restricts a query on workspace-owned rows, such as catalogs, tags
+// and folders, to the scope's workspace.
+func workspaceOwned(tx *gorm.DB, scope Scope) *gorm.DB {
+	if scope.OrgID != nil {
+		return tx.Where("org_id = ?", *scope.OrgID)
+	}
+	return tx.Where("org_id IS NULL AND user_id = ?", scope.UserID)
+}
+
+// referencedBy restricts a query on catalogs, tags or folders to the rows whose IDs
+// the subquery ids selects from the scope's templates, if the scope is limited to
+// some templates. Otherwise the query is returned unchanged, since the rest of the
+// workspace is visible.
+func referencedBy(tx *gorm.DB, scope Scope, ids func(templates *gorm.DB) *gorm.DB) *gorm.DB {
+	if len(scope.TemplateIDs) == 0 {
+		return tx
+	}
+
+	templates := workspaceTemplates(tx.Session(&gorm.Session{NewDB: true}).Model(&models.Template{}), scope)
+	return tx.Where("id IN (?)", ids(templates))
+}
+
+// CreateOrganization creates an organization with userID as its owner.
+func (d *Database) CreateOrganization(userID uuid.UUID, input models.CreateOrganizationAPI) (models.Organization, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return models.Organization{}, invalid("name_missing", "organization name is missing")
+	}
+
+	org := models.Organization{
+		ID:        uuid.New(),
+		Name:      input.Name,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.OrgMember{
+			ID:        uuid.New(),
+			OrgID:     org.ID,
+			UserID:    userID,
+			Role:      string(OrgRoleOwner),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}).Error
+	})
+
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	return org, nil
+}
+
+// GetOrganizations lists the organizations userID belongs to, with the user's role.
+func (d *Database) GetOrganizations(userID uuid.UUID) ([]models.OrganizationDTO, error) {
+	var members []models.OrgMember
+	if err := d.db.Where("user_id = ?", userID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make(map[uuid.UUID]string, len(members))
+	orgIDs := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		roles[m.OrgID] = m.Role
+		orgIDs = append(orgIDs, m.OrgID)
+	}
+
+	orgs := make([]models.OrganizationDTO, 0, len(members))
+	if len(orgIDs) == 0 {
+		return orgs, nil
+	}
+
+	var rows []models.Organization
+	if err := d.db.Where("id IN ?", orgIDs).Order("name").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, o := range rows {
+		orgs = append(orgs, models.OrganizationDTO{
+			ID:        o.ID.String(),
+			Name:      o.Name,
+			Role:      roles[o.ID],
+			CreatedAt: o.CreatedAt,
+		})
+	}
+
+	return orgs, nil
+}
+
+// GetOrgMembers lists the members of an organization. Any member may list them.
+func (d *Database) GetOrgMembers(userID uuid.UUID, orgIDStr string) ([]models.OrgMember, error) {
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil {
+		return nil, errNotOrgMember
+	}
+
+	if _, err := memberRole(d.db, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	var members []models.OrgMember
+	if err := d.db.Where("org_id = ?", orgID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// SetOrgMember adds a member or changes a member's role. Admins may manage
+// members; only owners may grant or take away the owner role.
+func (d *Database) SetOrgMember(userID uuid.UUID, orgIDStr string, input models.OrgMemberAPI) (models.OrgMember, error) {
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil {
+		return models.OrgMember{}, errNotOrgMember
+	}
+
+	role, err := ParseOrgRole(input.Role)
+	if err != nil {
+		return models.OrgMember{}, err
+	}
+
+	if input.UserID == uuid.Nil {
+		return models.OrgMember{}, invalid("user_missing", "user_id is missing")
+	}
+
+	var member models.OrgMember
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		callerRole, err := memberRole(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if !callerRole.Allows(OrgRoleAdmin) {
+			return forbidden("insufficient_role", "organization role %s required", OrgRoleAdmin)
+		}
+
+		err = tx.Where("org_id = ? AND user_id = ?", orgID, input.UserID).First(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if role == OrgRoleOwner && callerRole != OrgRoleOwner {
+				return forbidden("insufficient_role", "only owners can add owners")
+			}
+
+			member = models.OrgMember{
+				ID:        uuid.New(),
+				OrgID:     orgID,
+				UserID:    input.UserID,
+				Role:      string(role),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+			}
+			return tx.Create(&member).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if (role == OrgRoleOwner || OrgRole(member.Role) == OrgRoleOwner) && callerRole != OrgRoleOwner {
+			return forbidden("insufficient_role", "only owners can change owners")
+		}
+
+		if OrgRole(member.Role) == OrgRoleOwner && role != OrgRoleOwner {
+			if err := ensureAnotherOwner(tx, orgID, member.UserID); err != nil {
+				return err
+			}
+		}
+
+		member.Role = string(role)
+		member.UpdatedAt = time.Now()
+
+		return tx.Save(&member).Error
+	})
+
+	if err != nil {
+		return models.OrgMember{}, err
+	}
+
+	return member, nil
+}
+
+// RemoveOrgMember removes a member from an organization. Members may remove
+// themselves; removing others requires the admin role.
+func (d *Database) RemoveOrgMember(userID uuid.UUID, orgIDStr, memberIDStr string) error {
+	orgID, err := uuid.Parse(orgIDStr)
+	if err != nil {
+		return errNotOrgMember
+	}
+
+	memberID, err := uuid.Parse(memberIDStr)
+	if err != nil {
+		return errNotOrgMember
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		callerRole, err := memberRole(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+
+		targetRole, err := memberRole(tx, orgID, memberID)
+		if err != nil {
+			return err
+		}
+
+		if memberID != userID {
+			if !callerRole.Allows(OrgRoleAdmin) {
+				return forbidden("insufficient_role", "organization role %s required", OrgRoleAdmin)
+			}
+			if targetRole == OrgRoleOwner && callerRole != OrgRoleOwner {
+				return forbidden("insufficient_role", "only owners can remove owners")
+			}
+		}
+
+		if targetRole == OrgRoleOwner {
+			if err := ensureAnotherOwner(tx, orgID, memberID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().
+			Where("org_id = ? AND user_id = ?", orgID, memberID).
+			Delete(&models.OrgMember{}).Error
+	})
+}
+
+// ensureAnotherOwner fails if userID is the organization's only owner.
+func ensureAnotherOwner(tx *gorm.DB, orgID, userID uuid.UUID) error {
+	var owners int64
+	if err := tx.Model(&models.OrgMember{}).
+		Where("org_id = ? AND role = ? AND user_id <> ?", orgID, OrgRoleOwner, userID).
+		Count(&owners).Error; err != nil {
+		return err
+	}
+
+	if owners == 0 {
+		return conflict("last_owner", "organization must keep at least one owner")
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"sort"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateField is returned when an update defines a field key twice.
+var ErrDuplicateField = conflict("duplicate_field", "duplicate field key")
+
+// templatePatch is the document a JSON Merge Patch (RFC 7396) is applied to. Fields
+// are keyed by their key so that patches can change or remove a single field:
+//
+//	{"description": "Monthly", "fields": {"due": {"required": false}, "notes": null}}
+type templatePatch struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	CatalogID   *uuid.UUID            `json:"catalog_id"`
+	Fields      map[string]fieldPatch `json:"fields"`
+}
+
+type fieldPatch struct {
+	Label    string          `json:"label"`
+	Type     string          `json:"type"`
+	Required bool            `json:"required"`
+	Options  json.RawMessage `json:"options,omitempty"`
+}
+
+// PatchTemplate applies a JSON Merge Patch to the metadata and fields of a template.
+// Fields keep their order; fields added by the patch follow in key order. Patches
+// setting members other than name, description, catalog_id and fields fail with
+// invalid_patch.
+//
+// Parameters:
+//   - scope: The caller's workspace.
+//   - templateIDStr: The template ID.
+//   - patch: The merge patch document.
+//   - ifMatch: An If-Match header the template must match, or empty.
+//
+// Returns:
+//   - string: The entity tag of the updated template.
+//   - error: ErrTemplateNotFound, ErrInsufficientRole, ErrPreconditionFailed,
+//     ErrDuplicateField or a validation or database error.
+func (d *Database) PatchTemplate(scope Scope, templateIDStr string, patch json.RawMessage, ifMatch string) (string, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return "", ErrTemplateNotFound
+	}
+
+	var changes any
+	if err := json.Unmarshal(patch, &changes); err != nil {
+		return "", invalid("invalid_patch", "invalid merge patch: %v", err)
+	}
+
+	var etag string
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		template, err := authorizeTemplate(tx, templateID, scope, RoleEditor)
+		if err != nil {
+			return err
+		}
+
+		if err := checkIfMatch(tx, template, ifMatch); err != nil {
+			return err
+		}
+
+		if err := tx.Where("template_id = ?", templateID).Order("created_at").Find(&template.Fields).Error; err != nil {
+			return err
+		}
+
+		current := templatePatch{
+			Name:        template.Name,
+			Description: template.Description,
+			CatalogID:   template.CatalogID,
+			Fields:      make(map[string]fieldPatch, len(template.Fields)),
+		}
+		order := make([]string, 0, len(template.Fields))
+		for _, f := range template.Fields {
+			current.Fields[f.Key] = fieldPatch{
+				Label:    f.Label,
+				Type:     f.Type,
+				Required: f.Required,
+				Options:  json.RawMessage(f.Options),
+			}
+			order = append(order, f.Key)
+		}
+
+		var doc any
+		raw, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return err
+		}
+
+		raw, err = json.Marshal(mergePatch(doc, changes))
+		if err != nil {
+			return err
+		}
+
+		patched, err := decodePatched(raw)
+		if err != nil {
+			return err
+		}
+
+		// Type, category and content cannot be patched and are kept as they are.
+		head, err := latestVersion(tx, templateID)
+		if err != nil {
+			return err
+		}
+		input := patched.input(order)
+		input.Type = template.Type
+		input.Category = template.Category
+		input.Content = head.Content
+
+		etag, err = updateTemplate(tx, template, input, scope.UserID)
+		return err
+	})
+
+	return etag, err
+}
+
+// decodePatched decodes a patched document. Members that cannot be patched, such
+// as type or is_public, are rejected rather than silently dropped.
+func decodePatched(raw []byte) (templatePatch, error) {
+	var patched templatePatch
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&patched); err != nil {
+		return patched, unprocessable("invalid_patch", "invalid merge patch: %v", err)
+	}
+
+	return patched, nil
+}
+
+// input converts the patched document to an update, ordering fields by their
+// previous position.
+func (p templatePatch) input(order []string) models.CreateTemplateAPI {
+	keys := make([]string, 0, len(p.Fields))
+	for key := range p.Fields {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		pi, pj := slices.Index(order, keys[i]), slices.Index(order, keys[j])
+		switch {
+		case pi >= 0 && pj >= 0:
+			return pi < pj
+		case pi >= 0 || pj >= 0:
+			return pi >= 0
+		}
+		return keys[i] < keys[j]
+	})
+
+	input := models.CreateTemplateAPI{
+		Name:        p.Name,
+		Description: p.Description,
+		CatalogID:   p.CatalogID,
+		Fields:      make([]models.TemplateFieldAPI, 0, len(keys)),
+	}
+	for _, key := range keys {
+		f := p.Fields[key]
+		input.Fields = append(input.Fields, models.TemplateFieldAPI{
+			Key:      key,
+			Label:    f.Label,
+			Type:     f.Type,
+			Required: f.Required,
+			Options:  f.Options,
+		})
+	}
+
+	return input
+}
+
+// mergePatch applies an RFC 7396 merge patch to a decoded JSON document. Objects
+// are merged recursively, null members are removed and other values replace the
+// target.
+func mergePatch(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	doc, ok := target.(map[string]any)
+	if !ok {
+		doc = make(map[string]any, len(changes))
+	}
+
+	for key, value := range changes {
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = mergePatch(doc[key], value)
+	}
+
+	return doc
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMergePatch runs the examples of RFC 7396, appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{target: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{target: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{target: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{target: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{target: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{target: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{target: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{target: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{target: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{target: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{target: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			var target, patch any
+			require.NoError(t, json.Unmarshal([]byte(tt.target), &target))
+			require.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+
+			got, err := json.Marshal(mergePatch(target, patch))
+			require.NoError(t, err)
+			require.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+// TestTemplatePatchInput verifies that patched fields keep their previous order.
+func TestTemplatePatchInput(t *testing.T) {
+	p := templatePatch{
+		Name: "Invoice",
+		Fields: map[string]fieldPatch{
+			"total":  {Label: "Total"},
+			"zip":    {Label: "ZIP"},
+			"amount": {Label: "Amount"},
+			"name":   {Label: "Name"},
+		},
+	}
+
+	input := p.input([]string{"name", "removed", "total"})
+
+	keys := make([]string, 0, len(input.Fields))
+	for _, f := range input.Fields {
+		keys = append(keys, f.Key)
+	}
+	require.Equal(t, []string{"name", "total", "amount", "zip"}, keys)
+}
+
+// TestDecodePatched verifies that members which cannot be patched are rejected.
+func TestDecodePatched(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		ok   bool
+	}{
+		{name: "supported members", doc: `{"name":"Invoice","fields":{"due":{"label":"Due","type":"date"}}}`, ok: true},
+		{name: "type", doc: `{"name":"Invoice","type":"pdf"}`},
+		{name: "is_public", doc: `{"name":"Invoice","is_public":true}`},
+		{name: "unknown field member", doc: `{"fields":{"due":{"label":"Due","colour":"red"}}}`},
+		{name: "wrong type", doc: `{"name":5}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodePatched([]byte(tt.doc))
+			if tt.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, unprocessable("invalid_patch", ""))
+		})
+	}
+}
+
+// TestCheckUpdate verifies that patched documents without a name or with
+// incomplete fields are rejected.
+func TestCheckUpdate(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		code string
+	}{
+		{name: "valid", doc: `{"name":"Invoice","fields":{"due":{"label":"Due"}}}`},
+		{name: "name removed", doc: `{"fields":{}}`, code: "name_missing"},
+		{name: "blank name", doc: `{"name":"  "}`, code: "name_missing"},
+		{name: "empty field", doc: `{"name":"Invoice","fields":{"due":{}}}`, code: "field_invalid"},
+		{name: "blank key", doc: `{"name":"Invoice","fields":{" ":{"label":"Due"}}}`, code: "field_invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := decodePatched([]byte(tt.doc))
+			require.NoError(t, err)
+
+			_, err = checkUpdate(patched.input(nil))
+			if tt.code == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, invalid(tt.code, ""))
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Retention policy sources.
+const (
+	retentionOrg    = "org"
+	retentionGlobal = "global"
+)
+
+// RetentionRules decide which history entries are purged. An entry is purged when
+// it is older than MaxAgeDays or not among the KeepLast most recent entries of its
+// template. Zero disables a rule.
+type RetentionRules struct {
+	MaxAgeDays int
+	KeepLast   int
+}
+
+// Enabled reports whether the rules purge anything.
+func (r RetentionRules) Enabled() bool {
+	return r.MaxAgeDays > 0 || r.KeepLast > 0
+}
+
+func (r RetentionRules) validate() error {
+	if r.MaxAgeDays < 0 || r.KeepLast < 0 {
+		return invalid("invalid_retention", "retention values must not be negative")
+	}
+	return nil
+}
+
+// purgeCandidates selects the IDs, templates and times of the history entries the
+// rules purge. Organization policies override the global rules for their templates.
+// The workspace condition, if any, is applied to the joined templates table t.
+func purgeCandidates(tx *gorm.DB, global RetentionRules, workspace string, args ...any) *gorm.DB {
+	ranked := tx.Table("template_histories AS h").
+		Select(`h.id, h.template_id, h.used_at,
+			ROW_NUMBER() OVER (PARTITION BY h.template_id ORDER BY h.used_at DESC, h.id DESC) AS rn,
+			COALESCE(p.max_age_days, ?) AS max_age_days,
+			COALESCE(p.keep_last, ?) AS keep_last`, global.MaxAgeDays, global.KeepLast).
+		Joins("JOIN templates AS t ON t.id = h.template_id").
+		Joins("LEFT JOIN retention_policies AS p ON p.org_id = t.org_id AND p.deleted_at IS NULL")
+
+	if workspace != "" {
+		ranked = ranked.Where(workspace, args...)
+	}
+
+	return tx.Table("(?) AS x", ranked).
+		Where(`(x.max_age_days > 0 AND x.used_at < ?::timestamptz - x.max_age_days * interval '1 day')
+			OR (x.keep_last > 0 AND x.rn > x.keep_last)`, time.Now())
+}
+
+// PurgeHistory hard-deletes the history entries the retention rules expire, in
+// batches so that no single statement locks many rows.
+//
+// Parameters:
+//   - global: The rules for templates without an organization policy.
+//   - batchSize: The maximum number of entries deleted per statement.
+//
+// Returns:
+//   - int64: The number of entries deleted.
+//   - error: An error if a batch fails; earlier batches stay deleted.
+func (d *Database) PurgeHistory(global RetentionRules, batchSize int) (int64, error) {
+	var total int64
+
+	for {
+		batch := purgeCandidates(d.db, global, "").Select("x.id").Limit(batchSize)
+
+		result := d.db.Unscoped().Where("id IN (?)", batch).Delete(&models.TemplateHistory{})
+		if result.Error != nil {
+			return total, result.Error
+		}
+
+		total += result.RowsAffected
+		if result.RowsAffected < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// GetRetentionPolicy returns the rules in effect for the scope's workspace.
+func (d *Database) GetRetentionPolicy(scope Scope, global RetentionRules) (models.RetentionPolicyDTO, error) {
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return models.RetentionPolicyDTO{}, err
+	}
+
+	return effectiveRetention(d.db, scope, global)
+}
+
+// SetRetentionPolicy sets the organization's retention rules. Organization admins
+// may shorten or extend retention relative to the global rules.
+func (d *Database) SetRetentionPolicy(scope Scope, input models.RetentionPolicyAPI) (models.RetentionPolicyDTO, error) {
+	rules := RetentionRules{MaxAgeDays: input.MaxAgeDays, KeepLast: input.KeepLast}
+	if err := rules.validate(); err != nil {
+		return models.RetentionPolicyDTO{}, err
+	}
+
+	if scope.OrgID == nil {
+		return models.RetentionPolicyDTO{}, invalid("organization_required", "retention policies can only be set for organizations")
+	}
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleAdmin); err != nil {
+			return err
+		}
+
+		var policy models.RetentionPolicy
+		err := tx.Where("org_id = ?", *scope.OrgID).First(&policy).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			policy = models.RetentionPolicy{
+				ID:        uuid.New(),
+				OrgID:     *scope.OrgID,
+				CreatedAt: time.Now(),
+			}
+		} else if err != nil {
+			return err
+		}
+
+		policy.MaxAgeDays = rules.MaxAgeDays
+		policy.KeepLast = rules.KeepLast
+		policy.UpdatedBy = scope.UserID
+		policy.UpdatedAt = time.Now()
+
+		return tx.Save(&policy).Error
+	})
+
+	if err != nil {
+		return models.RetentionPolicyDTO{}, err
+	}
+
+	return models.RetentionPolicyDTO{
+		MaxAgeDays: rules.MaxAgeDays,
+		KeepLast:   rules.KeepLast,
+		Source:     retentionOrg,
+	}, nil
+}
+
+// DeleteRetentionPolicy reverts the organization to the global rules.
+func (d *Database) DeleteRetentionPolicy(scope Scope) error {
+	if scope.OrgID == nil {
+		return invalid("organization_required", "retention policies can only be set for organizations")
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleAdmin); err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("org_id = ?", *scope.OrgID).Delete(&models.RetentionPolicy{}).Error
+	})
+}
+
+// PreviewPurge reports, per template, the history entries of the scope's workspace
+// the next purge would remove. Organization previews require the admin role; the
+// personal preview covers the caller's own templates.
+func (d *Database) PreviewPurge(scope Scope, global RetentionRules) (models.PurgeReportDTO, error) {
+	var report models.PurgeReportDTO
+
+	workspace, args := "t.org_id IS NULL AND t.user_id = ?", []any{scope.UserID}
+	if scope.OrgID != nil {
+		if err := requireOrgRole(d.db, scope, OrgRoleAdmin); err != nil {
+			return report, err
+		}
+		workspace, args = "t.org_id = ?", []any{*scope.OrgID}
+	}
+
+	policy, err := effectiveRetention(d.db, scope, global)
+	if err != nil {
+		return report, err
+	}
+	report.Policy = policy
+
+	report.Templates = make([]models.PurgeCandidateDTO, 0)
+	if err := purgeCandidates(d.db, global, workspace, args...).
+		Select(`x.template_id, MAX(t.name) AS template_name, COUNT(*) AS entries,
+			MIN(x.used_at) AS oldest, MAX(x.used_at) AS newest`).
+		Joins("JOIN templates AS t ON t.id = x.template_id").
+		Group("x.template_id").
+		Order("entries DESC").
+		Scan(&report.Templates).Error; err != nil {
+		return report, err
+	}
+
+	for _, c := range report.Templates {
+		report.Total += c.Entries
+	}
+
+	return report, nil
+}
+
+// effectiveRetention returns the organization policy of the scope, or the global
+// rules for personal workspaces and organizations without a policy.
+func effectiveRetention(tx *gorm.DB, scope Scope, global RetentionRules) (models.RetentionPolicyDTO, error) {
+	dto := models.RetentionPolicyDTO{
+		MaxAgeDays: global.MaxAgeDays,
+		KeepLast:   global.KeepLast,
+		Source:     retentionGlobal,
+	}
+
+	if scope.OrgID == nil {
+		return dto, nil
+	}
+
+	var policy models.RetentionPolicy
+	err := tx.Where("org_id = ?", *scope.OrgID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto, nil
+	}
+	if err != nil {
+		return dto, err
+	}
+
+	return models.RetentionPolicyDTO{
+		MaxAgeDays: policy.MaxAgeDays,
+		KeepLast:   policy.KeepLast,
+		Source:     retentionOrg,
+	}, nil
+}
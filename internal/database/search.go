@@ -0,0 +1,99 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/dashboard-platform/template-service/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// searchConfig is the text search configuration of the search vector and queries.
+	searchConfig = "english"
+
+	// escapedContent is the indexed content with HTML special characters escaped.
+	// Content is raw HTML and Handlebars; escaping it before ts_headline leaves the
+	// <mark> highlights as the only markup in a snippet.
+	escapedContent = `replace(replace(replace(replace(replace(
+		coalesce(templates.search_content, ''),
+		'&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+)
+
+// migrateSearch adds the generated search vector of templates and its GIN index,
+// and fills in the indexed content of templates created before it existed.
+func migrateSearch(db *gorm.DB) error {
+	statements := []string{
+		`ALTER TABLE templates ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('` + searchConfig + `', coalesce(name, '')), 'A') ||
+				setweight(to_tsvector('` + searchConfig + `', coalesce(description, '')), 'B') ||
+				setweight(to_tsvector('` + searchConfig + `', coalesce(search_content, '')), 'C')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_templates_search_vector ON templates USING GIN (search_vector)`,
+		`UPDATE templates SET search_content = latest.content
+			FROM (
+				SELECT DISTINCT ON (template_id) template_id, content
+				FROM template_versions
+				WHERE deleted_at IS NULL
+				ORDER BY template_id, version DESC
+			) AS latest
+			WHERE latest.template_id = templates.id AND templates.search_content IS NULL`,
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SearchTemplates runs a full-text search over the names, descriptions and latest
+// content of the templates visible in the scope. The query accepts web search
+// syntax: quoted phrases, "or" and "-" for exclusion. Snippets are HTML-escaped
+// text in which the matches are wrapped in <mark> elements.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - q: The search query.
+//   - limit: The maximum number of results, capped at maxSearchLimit.
+//
+// Returns:
+//   - []models.TemplateSearchResultDTO: The matches, most relevant first.
+//   - error: An error if the query is empty or the search fails.
+func (d *Database) SearchTemplates(scope Scope, q string, limit int) ([]models.TemplateSearchResultDTO, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, invalid("query_missing", "search query is missing")
+	}
+
+	if limit < 1 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.TemplateSearchResultDTO, 0)
+	if err := workspaceTemplates(d.db.Model(&models.Template{}), scope).
+		Select(summaryColumns+`,
+			ts_rank(templates.search_vector, tsq) AS rank,
+			ts_headline('`+searchConfig+`', `+escapedContent+`, tsq,
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS snippet`).
+		Joins("CROSS JOIN websearch_to_tsquery('"+searchConfig+"', ?) AS tsq", q).
+		Where("templates.search_vector @@ tsq").
+		Order("rank DESC, templates.updated_at DESC").
+		Limit(limit).
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role is a user's permission level on a template.
+type Role string
+
+const (
+	RoleViewer Role = "viewer" // Can read and render the template.
+	RoleEditor Role = "editor" // Can also update the template.
+	RoleOwner  Role = "owner"  // Can also delete, publish and share the template.
+)
+
+var (
+	// ErrTemplateNotFound is returned for templates that do not exist or are not
+	// visible to the caller.
+	ErrTemplateNotFound = notFound("template_not_found", "template not found or not accessible")
+
+	// ErrInsufficientRole is returned when the caller's role on a template is too low.
+	ErrInsufficientRole = forbidden("insufficient_role", "insufficient permissions on template")
+
+	// errShareNotFound is returned for shares that do not exist.
+	errShareNotFound = notFound("share_not_found", "share not found")
+)
+
+// rank orders roles so that a higher rank includes the permissions of lower ones.
+func (r Role) rank() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleEditor:
+		return 2
+	case RoleOwner:
+		return 3
+	}
+	return 0
+}
+
+// Allows reports whether r grants at least the permissions of required.
+func (r Role) Allows(required Role) bool {
+	return r.rank() > 0 && r.rank() >= required.rank()
+}
+
+// ParseRole validates a role name.
+func ParseRole(s string) (Role, error) {
+	r := Role(s)
+	if r.rank() == 0 {
+		return "", invalid("invalid_role", "invalid role %q", s)
+	}
+	return r, nil
+}
+
+// authorizeTemplate loads a template from the scope's workspace and checks that the
+// caller holds at least the required role on it. Templates outside the workspace or
+// invisible to the caller are reported as not found.
+func authorizeTemplate(tx *gorm.DB, templateID uuid.UUID, scope Scope, required Role) (models.Template, error) {
+	var template models.Template
+
+	if err := tx.Where("id = ?", templateID).First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return template, ErrTemplateNotFound
+		}
+		return template, err
+	}
+
+	if !sameWorkspace(template.OrgID, scope) {
+		return template, ErrTemplateNotFound
+	}
+
+	if len(scope.TemplateIDs) > 0 && !slices.Contains(scope.TemplateIDs, template.ID) {
+		return template, ErrTemplateNotFound
+	}
+
+	role, err := templateRole(tx, template, scope.UserID)
+	if err != nil {
+		return template, err
+	}
+
+	if role == "" {
+		return template, ErrTemplateNotFound
+	}
+
+	if !role.Allows(required) {
+		return template, ErrInsufficientRole
+	}
+
+	return template, nil
+}
+
+// templateRole returns the user's role on a template, or an empty role if none.
+// Organization templates grant the role implied by the user's membership; explicit
+// shares can raise it further.
+func templateRole(tx *gorm.DB, template models.Template, userID uuid.UUID) (Role, error) {
+	var role Role
+
+	if template.OrgID != nil {
+		orgRole, err := memberRole(tx, *template.OrgID, userID)
+		if errors.Is(err, errNotOrgMember) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		role = orgRole.templateRole()
+	} else if template.UserID == userID {
+		return RoleOwner, nil
+	}
+
+	var share models.TemplateShare
+	err := tx.Where("template_id = ? AND user_id = ?", template.ID, userID).First(&share).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return role, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if Role(share.Role).rank() > role.rank() {
+		role = Role(share.Role)
+	}
+
+	return role, nil
+}
+
+// sharedTemplateIDs is a subquery selecting the templates shared with userID.
+func sharedTemplateIDs(tx *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&models.TemplateShare{}).
+		Select("template_id").
+		Where("user_id = ?", userID)
+}
+
+// GrantShare gives a user a role on a template, replacing any existing grant.
+// Only owners may share a template. Organization templates can only be shared
+// with members of the organization, since shares never reach across it.
+func (d *Database) GrantShare(scope Scope, templateIDStr string, input models.ShareAPI) (models.TemplateShare, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return models.TemplateShare{}, ErrTemplateNotFound
+	}
+
+	role, err := ParseRole(input.Role)
+	if err != nil {
+		return models.TemplateShare{}, err
+	}
+
+	if input.UserID == uuid.Nil {
+		return models.TemplateShare{}, invalid("user_missing", "user_id is missing")
+	}
+
+	var share models.TemplateShare
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		template, err := authorizeTemplate(tx, templateID, scope, RoleOwner)
+		if err != nil {
+			return err
+		}
+
+		if input.UserID == template.UserID {
+			return invalid("share_with_owner", "cannot share a template with its owner")
+		}
+
+		if template.OrgID != nil {
+			_, err := memberRole(tx, *template.OrgID, input.UserID)
+			if errors.Is(err, errNotOrgMember) {
+				e := unprocessable("share_not_member", "user is not a member of the template's organization")
+				e.Details = map[string]any{"user_id": input.UserID}
+				return e
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tx.Where("template_id = ? AND user_id = ?", templateID, input.UserID).First(&share).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			share = models.TemplateShare{
+				ID:         uuid.New(),
+				TemplateID: templateID,
+				UserID:     input.UserID,
+				Role:       string(role),
+				GrantedBy:  scope.UserID,
+				CreatedAt:  time.Now(),
+				UpdatedAt:  time.Now(),
+			}
+			return tx.Create(&share).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		share.Role = string(role)
+		share.GrantedBy = scope.UserID
+		share.UpdatedAt = time.Now()
+
+		return tx.Save(&share).Error
+	})
+
+	if err != nil {
+		return models.TemplateShare{}, err
+	}
+
+	return share, nil
+}
+
+// GetShares lists the grants on a template. Any user with access may list them.
+func (d *Database) GetShares(scope Scope, templateIDStr string) ([]models.TemplateShare, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	if _, err := authorizeTemplate(d.db, templateID, scope, RoleViewer); err != nil {
+		return nil, err
+	}
+
+	var shares []models.TemplateShare
+	if err := d.db.Where("template_id = ?", templateID).Order("created_at").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// RevokeShare removes a user's grant on a template. Only owners may revoke grants.
+func (d *Database) RevokeShare(scope Scope, templateIDStr, granteeIDStr string) error {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return ErrTemplateNotFound
+	}
+
+	granteeID, err := uuid.Parse(granteeIDStr)
+	if err != nil {
+		return errShareNotFound
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := authorizeTemplate(tx, templateID, scope, RoleOwner); err != nil {
+			return err
+		}
+
+		res := tx.Unscoped().
+			Where("template_id = ? AND user_id = ?", templateID, granteeID).
+			Delete(&models.TemplateShare{})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return errShareNotFound
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ShareLinkVersion checks that the caller may create share links for a template and
+// resolves the version a link is bound to.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - templateIDStr: The template ID.
+//   - version: The requested version, or 0 for the latest one.
+//
+// Returns:
+//   - uuid.UUID: The template ID.
+//   - int: The version the link grants access to.
+//   - error: An error if the template or version is not accessible.
+func (d *Database) ShareLinkVersion(scope Scope, templateIDStr string, version int) (uuid.UUID, int, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return uuid.Nil, 0, ErrTemplateNotFound
+	}
+
+	if _, err := authorizeTemplate(d.db, templateID, scope, RoleOwner); err != nil {
+		return uuid.Nil, 0, err
+	}
+
+	var v models.TemplateVersion
+	query := d.db.Where("template_id = ?", templateID)
+	if version > 0 {
+		query = query.Where("version = ?", version)
+	} else {
+		query = query.Order("version DESC")
+	}
+
+	if err := query.First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, 0, notFound("version_not_found", "template version %d not found", version)
+		}
+		return uuid.Nil, 0, err
+	}
+
+	return templateID, v.Version, nil
+}
+
+// GetSharedTemplate loads a template for a verified share link, with Versions
+// holding only the linked version. Deleted templates are not found.
+func (d *Database) GetSharedTemplate(templateID uuid.UUID, version int) (models.Template, error) {
+	var template models.Template
+
+	if err := d.db.
+		Preload("Fields").
+		Preload("Versions", "version = ?", version).
+		Where("id = ?", templateID).
+		First(&template).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return template, ErrTemplateNotFound
+		}
+		return template, err
+	}
+
+	if len(template.Versions) == 0 {
+		return template, notFound("version_not_found", "template version %d not found", version)
+	}
+
+	return template, nil
+}
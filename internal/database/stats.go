@@ -0,0 +1,200 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultStatsBucket = "day"
+	defaultStatsRange  = 30 * 24 * time.Hour
+	defaultTopLimit    = 10
+	maxTopLimit        = 100
+)
+
+// statsBuckets maps bucket sizes to the longest range they may be requested for,
+// which bounds the number of buckets returned.
+var statsBuckets = map[string]time.Duration{
+	"hour": 31 * 24 * time.Hour,
+	"day":  366 * 24 * time.Hour,
+	"week": 5 * 366 * 24 * time.Hour,
+}
+
+// StatsFilter selects the time range and bucket size of usage statistics.
+type StatsFilter struct {
+	Bucket string    // hour, day or week.
+	From   time.Time // Inclusive start, defaults to 30 days before To.
+	To     time.Time // Exclusive end, defaults to now.
+	Limit  int       // Number of top templates, capped at maxTopLimit.
+}
+
+// Normalize applies defaults and validates the bucket size and range.
+func (f *StatsFilter) Normalize() error {
+	if f.Bucket == "" {
+		f.Bucket = defaultStatsBucket
+	}
+
+	maxRange, ok := statsBuckets[f.Bucket]
+	if !ok {
+		return invalid("invalid_bucket", "invalid bucket %q, expected hour, day or week", f.Bucket)
+	}
+
+	if f.To.IsZero() {
+		f.To = time.Now()
+	}
+	if f.From.IsZero() {
+		f.From = f.To.Add(-defaultStatsRange)
+	}
+	if !f.From.Before(f.To) {
+		return invalid("invalid_range", "from must be before to")
+	}
+	if f.To.Sub(f.From) > maxRange {
+		return invalid("invalid_range", "range too long for %s buckets", f.Bucket)
+	}
+
+	if f.Limit < 1 {
+		f.Limit = defaultTopLimit
+	}
+	if f.Limit > maxTopLimit {
+		f.Limit = maxTopLimit
+	}
+
+	return nil
+}
+
+// distinctUsers counts the distinct users of history rows, leaving out anonymous
+// share link renders. Its argument is uuid.Nil.
+const distinctUsers = "COUNT(DISTINCT user_id) FILTER (WHERE user_id <> ?)"
+
+// GetTemplateStats aggregates the renders of a template the caller can read.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - templateIDStr: The template ID.
+//   - filter: The time range and bucket size.
+//
+// Returns:
+//   - models.StatsDTO: Render counts per bucket and per version.
+//   - error: An error if the filter is invalid, the template is not accessible or
+//     a query fails.
+func (d *Database) GetTemplateStats(scope Scope, templateIDStr string, filter StatsFilter) (models.StatsDTO, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return models.StatsDTO{}, ErrTemplateNotFound
+	}
+
+	if err := filter.Normalize(); err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	if _, err := authorizeTemplate(d.db, templateID, scope, RoleViewer); err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	rows := func() *gorm.DB {
+		return d.db.Model(&models.TemplateHistory{}).
+			Where("template_id = ? AND used_at >= ? AND used_at < ?", templateID, filter.From, filter.To)
+	}
+
+	stats, err := d.stats(rows, filter)
+	if err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	stats.Versions = make([]models.VersionStatsDTO, 0)
+	if err := rows().
+		Select("version, COUNT(*) AS renders, "+distinctUsers+" AS users, MAX(used_at) AS last_used_at", uuid.Nil).
+		Group("version").
+		Order("version DESC").
+		Scan(&stats.Versions).Error; err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	return stats, nil
+}
+
+// GetUsageStats aggregates the renders of every template in the scope's workspace
+// and ranks the most rendered ones.
+func (d *Database) GetUsageStats(scope Scope, filter StatsFilter) (models.StatsDTO, error) {
+	if err := filter.Normalize(); err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	templateIDs := workspaceTemplates(d.db.Model(&models.Template{}), scope).Select("templates.id")
+
+	rows := func() *gorm.DB {
+		return d.db.Model(&models.TemplateHistory{}).
+			Where("template_histories.template_id IN (?)", templateIDs).
+			Where("template_histories.used_at >= ? AND template_histories.used_at < ?", filter.From, filter.To)
+	}
+
+	stats, err := d.stats(rows, filter)
+	if err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	stats.TopTemplates = make([]models.TopTemplateDTO, 0)
+	if err := rows().
+		Select(`template_histories.template_id, templates.name, COUNT(*) AS renders,
+			COUNT(DISTINCT template_histories.user_id) FILTER (WHERE template_histories.user_id <> ?) AS users,
+			MAX(template_histories.used_at) AS last_used_at`, uuid.Nil).
+		Joins("JOIN templates ON templates.id = template_histories.template_id").
+		Group("template_histories.template_id, templates.name").
+		Order("renders DESC, last_used_at DESC").
+		Limit(filter.Limit).
+		Scan(&stats.TopTemplates).Error; err != nil {
+		return models.StatsDTO{}, err
+	}
+
+	return stats, nil
+}
+
+// stats computes the totals and the gap-free bucket series of the history rows
+// selected by rows, which must return a fresh query on each call.
+func (d *Database) stats(rows func() *gorm.DB, filter StatsFilter) (models.StatsDTO, error) {
+	stats := models.StatsDTO{
+		From:    filter.From,
+		To:      filter.To,
+		Bucket:  filter.Bucket,
+		Buckets: make([]models.StatsBucketDTO, 0),
+	}
+
+	var totals struct {
+		Renders int64
+		Users   int64
+	}
+	if err := rows().
+		Select("COUNT(*) AS renders, "+distinctUsers+" AS users", uuid.Nil).
+		Scan(&totals).Error; err != nil {
+		return stats, err
+	}
+	stats.TotalRenders = totals.Renders
+	stats.DistinctUsers = totals.Users
+
+	// The bucket is one of statsBuckets, so it is safe to interpolate.
+	perBucket := rows().
+		Select(fmt.Sprintf("date_trunc('%s', used_at) AS bucket, COUNT(*) AS renders, %s AS users",
+			filter.Bucket, distinctUsers), uuid.Nil).
+		Group("1")
+
+	series := fmt.Sprintf(`SELECT s.bucket AS start, COALESCE(h.renders, 0) AS renders, COALESCE(h.users, 0) AS users
+		FROM generate_series(date_trunc('%[1]s', ?::timestamptz), ?::timestamptz, interval '1 %[1]s') AS s(bucket)
+		LEFT JOIN (?) AS h ON h.bucket = s.bucket
+		WHERE s.bucket < ?
+		ORDER BY s.bucket`, filter.Bucket)
+
+	if err := d.db.Raw(series, filter.From, filter.To, perBucket, filter.To).
+		Scan(&stats.Buckets).Error; err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestStatsFilterNormalize verifies stats defaults and range limits.
+func TestStatsFilterNormalize(t *testing.T) {
+	f := StatsFilter{}
+	require.NoError(t, f.Normalize())
+	require.Equal(t, "day", f.Bucket)
+	require.Equal(t, defaultStatsRange, f.To.Sub(f.From))
+	require.Equal(t, defaultTopLimit, f.Limit)
+
+	now := time.Now()
+	tests := []struct {
+		name   string
+		filter StatsFilter
+	}{
+		{name: "unknown bucket", filter: StatsFilter{Bucket: "minute"}},
+		{name: "inverted range", filter: StatsFilter{From: now, To: now.Add(-time.Hour)}},
+		{name: "too many hours", filter: StatsFilter{Bucket: "hour", From: now.AddDate(0, -3, 0), To: now}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, tt.filter.Normalize())
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errTagNotFound is returned for tags outside the scope's workspace.
+var errTagNotFound = notFound("tag_not_found", "tag not found")
+
+// migrateTags adds the indexes keeping tag names unique within a workspace.
+func migrateTags(db *gorm.DB) error {
+	statements := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_org_name ON tags (org_id, name)
+			WHERE org_id IS NOT NULL AND deleted_at IS NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags (user_id, name)
+			WHERE org_id IS NULL AND deleted_at IS NULL`,
+	}
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateTag adds a tag to the scope's workspace. Creating an existing tag returns it.
+func (d *Database) CreateTag(scope Scope, input models.TagAPI) (models.Tag, error) {
+	var tag models.Tag
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		var err error
+		tag, err = findOrCreateTag(tx, scope, input.Name)
+		return err
+	})
+
+	if err != nil {
+		return models.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// GetTags returns every tag of the scope's workspace. Scopes limited to some
+// templates see only the tags of those templates.
+func (d *Database) GetTags(scope Scope) ([]models.Tag, error) {
+	var tags []models.Tag
+
+	if err := requireOrgRole(d.db, scope, OrgRoleViewer); err != nil {
+		return nil, err
+	}
+
+	if err := workspaceOwned(referencedBy(d.db, scope, tagIDs), scope).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// tagIDs selects the tags of templates.
+func tagIDs(templates *gorm.DB) *gorm.DB {
+	return templates.Session(&gorm.Session{NewDB: true}).
+		Table("template_tags").
+		Select("tag_id").
+		Where("template_id IN (?)", templates.Select("templates.id"))
+}
+
+// DeleteTag removes a tag from the scope's workspace and from every template.
+func (d *Database) DeleteTag(scope Scope, tagIDStr string) error {
+	tagID, err := uuid.Parse(tagIDStr)
+	if err != nil {
+		return errTagNotFound
+	}
+
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleEditor); err != nil {
+			return err
+		}
+
+		var tag models.Tag
+		if err := workspaceOwned(tx, scope).Where("id = ?", tagID).First(&tag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errTagNotFound
+			}
+			return err
+		}
+
+		// The tags are part of the templates' entity tags.
+		if err := tx.Exec("UPDATE templates SET updated_at = ? WHERE id IN (SELECT template_id FROM template_tags WHERE tag_id = ?)",
+			time.Now().Truncate(time.Microsecond), tagID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM template_tags WHERE tag_id = ?", tagID).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&tag).Error
+	})
+}
+
+// SetTemplateTags replaces the tags of a template. Tags are looked up by name in
+// the template's workspace and created if missing.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - templateIDStr: The template ID.
+//   - names: The tag names; an empty list removes every tag.
+//
+// Returns:
+//   - []models.Tag: The template's new tags.
+//   - error: An error if the caller cannot edit the template or a name is invalid.
+func (d *Database) SetTemplateTags(scope Scope, templateIDStr string, names []string) ([]models.Tag, error) {
+	templateID, err := uuid.Parse(templateIDStr)
+	if err != nil {
+		return nil, ErrTemplateNotFound
+	}
+
+	tags := make([]models.Tag, 0, len(names))
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		template, err := authorizeTemplate(tx, templateID, scope, RoleEditor)
+		if err != nil {
+			return err
+		}
+
+		seen := map[string]bool{}
+		for _, name := range names {
+			tag, err := findOrCreateTag(tx, ownerScope(template), name)
+			if err != nil {
+				return err
+			}
+			if !seen[tag.Name] {
+				seen[tag.Name] = true
+				tags = append(tags, tag)
+			}
+		}
+
+		if err := tx.Model(&template).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+
+		return touchTemplate(tx, template.ID)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// findOrCreateTag returns the tag with the given name in the scope's workspace,
+// creating it if needed. Names are trimmed and matched exactly.
+func findOrCreateTag(tx *gorm.DB, scope Scope, name string) (models.Tag, error) {
+	var tag models.Tag
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return tag, invalid("name_missing", "tag name is missing")
+	}
+
+	err := workspaceOwned(tx, scope).Where("name = ?", name).First(&tag).Error
+	if err == nil {
+		return tag, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return tag, err
+	}
+
+	tag = models.Tag{
+		ID:        uuid.New(),
+		UserID:    scope.UserID,
+		OrgID:     scope.OrgID,
+		Name:      name,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := tx.Create(&tag).Error; err != nil {
+		return models.Tag{}, err
+	}
+
+	return tag, nil
+}
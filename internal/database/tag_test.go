@@ -0,0 +1,34 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetTagsRestricted verifies that scopes limited to some templates only list
+// the tags of those templates.
+func TestGetTagsRestricted(t *testing.T) {
+	d := testDatabase(t)
+	scope, first := testTemplate(t, d)
+
+	second, err := d.CreateTemplate(scope, testTemplateInput())
+	require.NoError(t, err)
+
+	_, err = d.SetTemplateTags(scope, first, []string{"billing"})
+	require.NoError(t, err)
+	_, err = d.SetTemplateTags(scope, second.String(), []string{"internal"})
+	require.NoError(t, err)
+
+	tags, err := d.GetTags(scope)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	restricted := scope
+	restricted.TemplateIDs = []uuid.UUID{uuid.MustParse(first)}
+	tags, err = d.GetTags(restricted)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, "billing", tags[0].Name)
+}
@@ -0,0 +1,506 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/netguard"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Webhook events.
+const (
+	EventTemplateCreated  = "template.created"  // A template was created, cloned or imported.
+	EventTemplateUpdated  = "template.updated"  // A template's metadata or fields changed.
+	EventTemplateDeleted  = "template.deleted"  // A template was deleted.
+	EventVersionPublished = "version.published" // A template gained a version.
+	EventTemplateRendered = "template.rendered" // A template was rendered.
+)
+
+// WebhookEvents lists every event webhooks may subscribe to.
+var WebhookEvents = []string{
+	EventTemplateCreated,
+	EventTemplateUpdated,
+	EventTemplateDeleted,
+	EventVersionPublished,
+	EventTemplateRendered,
+}
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// webhookSecretPrefix marks webhook signing secrets issued by this service.
+const webhookSecretPrefix = "whsec_"
+
+// webhookLookupTimeout bounds the DNS lookup of a webhook host at registration.
+const webhookLookupTimeout = 5 * time.Second
+
+// webhookLogRetention is how long finished deliveries remain in the delivery log.
+const webhookLogRetention = 30 * 24 * time.Hour
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// DueDelivery is a claimed delivery with the endpoint it is sent to.
+type DueDelivery struct {
+	models.WebhookDelivery
+	URL    string
+	Secret string
+}
+
+// DeliveryAttempt is the outcome of sending a delivery once.
+type DeliveryAttempt struct {
+	StatusCode int       // HTTP status of the response; 0 without a response.
+	Error      string    // Empty if the endpoint accepted the delivery.
+	RetryAt    time.Time // When to try again after a failure; zero to give up.
+}
+
+// CreateWebhook registers an endpoint for events of the scope's workspace.
+// Organization webhooks require the admin role. Endpoints must resolve to
+// publicly routable addresses.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - input: The endpoint URL and the events it subscribes to.
+//
+// Returns:
+//   - models.Webhook: The stored webhook, including its signing secret.
+//   - error: A role, validation or database error.
+func (d *Database) CreateWebhook(scope Scope, input models.WebhookAPI) (models.Webhook, error) {
+	u, err := url.Parse(input.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, invalid("invalid_url", "webhook url must be an absolute http or https URL")
+	}
+
+	// Deliveries must not reach the service's own network. The dispatcher checks
+	// again when it connects, since DNS answers can change.
+	ctx, cancel := context.WithTimeout(context.Background(), webhookLookupTimeout)
+	defer cancel()
+	if err := netguard.CheckHost(ctx, u.Hostname()); err != nil {
+		if errors.Is(err, netguard.ErrBlockedAddress) {
+			return models.Webhook{}, invalid("invalid_url", "webhook url must not point to a private or internal address")
+		}
+		return models.Webhook{}, invalid("invalid_url", "webhook host %q cannot be resolved", u.Hostname())
+	}
+
+	for _, e := range input.Events {
+		if !slices.Contains(WebhookEvents, e) {
+			err := invalid("invalid_event", "unknown webhook event %q", e)
+			err.Details = map[string]any{"event": e, "events": WebhookEvents}
+			return models.Webhook{}, err
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook := models.Webhook{
+		ID:        uuid.New(),
+		UserID:    scope.UserID,
+		OrgID:     scope.OrgID,
+		URL:       u.String(),
+		Secret:    webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(secret),
+		Events:    slices.Compact(slices.Sorted(slices.Values(input.Events))),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	stored := webhook
+	if d.cipher != nil {
+		if stored.EncryptedSecret, err = d.cipher.Seal([]byte(webhook.Secret), webhook.ID[:]); err != nil {
+			return models.Webhook{}, err
+		}
+		stored.Secret = ""
+	}
+
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := requireOrgRole(tx, scope, OrgRoleAdmin); err != nil {
+			return err
+		}
+
+		return tx.Create(&stored).Error
+	})
+
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// webhookSecret returns the signing secret of a stored webhook, opening it if it
+// is encrypted.
+func (d *Database) webhookSecret(webhook models.Webhook) (string, error) {
+	if webhook.EncryptedSecret == nil {
+		return webhook.Secret, nil
+	}
+	if d.cipher == nil {
+		return "", errors.New("webhook secret is encrypted and no key is configured")
+	}
+
+	secret, err := d.cipher.Open(webhook.EncryptedSecret, webhook.ID[:])
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// sealWebhookSecrets encrypts the signing secrets stored in plaintext, if a cipher
+// is set.
+func (d *Database) sealWebhookSecrets() error {
+	if d.cipher == nil {
+		return nil
+	}
+
+	var webhooks []models.Webhook
+	if err := d.db.Unscoped().Where("encrypted_secret IS NULL AND secret <> ''").Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		sealed, err := d.cipher.Seal([]byte(webhook.Secret), webhook.ID[:])
+		if err != nil {
+			return err
+		}
+		if err := d.db.Unscoped().Model(&webhook).UpdateColumns(map[string]any{
+			"encrypted_secret": sealed,
+			"secret":           "",
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetWebhooks returns the webhooks of the scope's workspace.
+func (d *Database) GetWebhooks(scope Scope) ([]models.Webhook, error) {
+	if err := requireOrgRole(d.db, scope, OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+
+	var webhooks []models.Webhook
+	if err := workspaceOwned(d.db, scope).Order("created_at DESC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// getWebhook loads a webhook of the scope's workspace.
+func getWebhook(tx *gorm.DB, scope Scope, idStr string) (models.Webhook, error) {
+	var webhook models.Webhook
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return webhook, notFound("webhook_not_found", "webhook not found")
+	}
+
+	if err := requireOrgRole(tx, scope, OrgRoleAdmin); err != nil {
+		return webhook, err
+	}
+
+	err = workspaceOwned(tx, scope).Where("id = ?", id).First(&webhook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return webhook, notFound("webhook_not_found", "webhook not found")
+	}
+
+	return webhook, err
+}
+
+// DeleteWebhook removes a webhook of the scope's workspace. Its pending deliveries
+// fail; its delivery log is kept until it expires.
+func (d *Database) DeleteWebhook(scope Scope, idStr string) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		webhook, err := getWebhook(tx, scope, idStr)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("webhook_id = ? AND status = ?", webhook.ID, DeliveryPending).
+			Updates(map[string]any{
+				"status":     DeliveryFailed,
+				"last_error": "webhook deleted",
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(&webhook).Error
+	})
+}
+
+// GetWebhookDeliveries returns the most recent deliveries of a webhook of the
+// scope's workspace, newest first.
+//
+// Parameters:
+//   - scope: The caller and workspace.
+//   - idStr: The webhook ID.
+//   - status: Only deliveries in this state, or every delivery if empty.
+//   - limit: The maximum number of deliveries returned, capped at maxDeliveryLimit.
+//
+// Returns:
+//   - []models.WebhookDelivery: The deliveries.
+//   - error: A not found, role, validation or database error.
+func (d *Database) GetWebhookDeliveries(scope Scope, idStr string, status string, limit int) ([]models.WebhookDelivery, error) {
+	webhook, err := getWebhook(d.db, scope, idStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 {
+		limit = defaultDeliveryLimit
+	}
+	limit = min(limit, maxDeliveryLimit)
+
+	query := d.db.Where("webhook_id = ?", webhook.ID)
+	switch status {
+	case "":
+	case DeliveryPending, DeliverySucceeded, DeliveryFailed:
+		query = query.Where("status = ?", status)
+	default:
+		return nil, invalid("invalid_status", "invalid delivery status %q", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// enqueueEvent writes an event about a template to the outbox. It must run in the
+// transaction that changes the template, so that events are recorded exactly when
+// the change commits.
+func enqueueEvent(tx *gorm.DB, event string, template models.Template, actorID uuid.UUID, data models.WebhookEventDataDTO) error {
+	data.TemplateID = template.ID.String()
+	data.TemplateName = template.Name
+	data.OrgID = template.OrgID
+	if actorID != uuid.Nil {
+		data.ActorID = &actorID
+	}
+
+	record := models.WebhookEvent{
+		ID:        uuid.New(),
+		Event:     event,
+		UserID:    template.UserID,
+		OrgID:     template.OrgID,
+		CreatedAt: time.Now(),
+	}
+
+	payload, err := json.Marshal(models.WebhookPayloadDTO{
+		ID:        record.ID.String(),
+		Event:     event,
+		CreatedAt: record.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+	record.Payload = payload
+
+	return tx.Create(&record).Error
+}
+
+// FanOutWebhookEvents turns the oldest outbox events into one pending delivery per
+// subscribed webhook of the event's workspace, and removes them from the outbox.
+// Concurrent callers skip each other's events.
+//
+// Parameters:
+//   - limit: The maximum number of events processed.
+//
+// Returns:
+//   - int: The number of deliveries created.
+//   - error: A database error.
+func (d *Database) FanOutWebhookEvents(limit int) (int, error) {
+	created := 0
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var events []models.WebhookEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Order("created_at, id").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+
+			var webhooks []models.Webhook
+			if err := workspaceOwned(tx, Scope{UserID: event.UserID, OrgID: event.OrgID}).Find(&webhooks).Error; err != nil {
+				return err
+			}
+
+			for _, webhook := range webhooks {
+				if !webhook.Subscribes(event.Event) {
+					continue
+				}
+
+				delivery := models.WebhookDelivery{
+					ID:            uuid.New(),
+					WebhookID:     webhook.ID,
+					EventID:       event.ID,
+					Event:         event.Event,
+					Payload:       event.Payload,
+					Status:        DeliveryPending,
+					NextAttemptAt: time.Now(),
+					CreatedAt:     time.Now(),
+					UpdatedAt:     time.Now(),
+				}
+				if err := tx.Create(&delivery).Error; err != nil {
+					return err
+				}
+				created++
+			}
+		}
+
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.WebhookEvent{}).Error
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return created, nil
+}
+
+// ClaimWebhookDeliveries leases the pending deliveries that are due. A claimed
+// delivery is not due again until the lease expires, so that a dispatcher that
+// dies mid-delivery does not lose it and concurrent dispatchers do not send it twice.
+//
+// Parameters:
+//   - limit: The maximum number of deliveries claimed.
+//   - lease: How long the caller has to record the attempt.
+//
+// Returns:
+//   - []DueDelivery: The claimed deliveries with their endpoints.
+//   - error: A database error.
+func (d *Database) ClaimWebhookDeliveries(limit int, lease time.Duration) ([]DueDelivery, error) {
+	var due []DueDelivery
+
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var deliveries []models.WebhookDelivery
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		webhookIDs := make([]uuid.UUID, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+
+		if err := tx.Model(&models.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+
+		var webhooks []models.Webhook
+		if err := tx.Where("id IN ?", webhookIDs).Find(&webhooks).Error; err != nil {
+			return err
+		}
+		endpoints := make(map[uuid.UUID]models.Webhook, len(webhooks))
+		for _, webhook := range webhooks {
+			endpoints[webhook.ID] = webhook
+		}
+
+		for _, delivery := range deliveries {
+			// Deliveries of deleted webhooks were failed when the webhook was deleted.
+			webhook, ok := endpoints[delivery.WebhookID]
+			if !ok {
+				continue
+			}
+			// Without its secret a delivery cannot be signed; it stays claimed until
+			// the lease expires and is retried then.
+			secret, err := d.webhookSecret(webhook)
+			if err != nil {
+				d.logger.Error().Err(err).Str("webhook_id", webhook.ID.String()).Msg("opening webhook secret failed")
+				continue
+			}
+			due = append(due, DueDelivery{WebhookDelivery: delivery, URL: webhook.URL, Secret: secret})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
+// RecordWebhookAttempt stores the outcome of sending a claimed delivery. The
+// delivery succeeds if the attempt has no error, is retried at RetryAt, or fails
+// for good if RetryAt is zero.
+func (d *Database) RecordWebhookAttempt(id uuid.UUID, attempt DeliveryAttempt) error {
+	now := time.Now()
+	updates := map[string]any{
+		"attempts":    gorm.Expr("attempts + 1"),
+		"last_status": attempt.StatusCode,
+		"last_error":  attempt.Error,
+		"updated_at":  now,
+	}
+
+	switch {
+	case attempt.Error == "":
+		updates["status"] = DeliverySucceeded
+		updates["delivered_at"] = now
+	case attempt.RetryAt.IsZero():
+		updates["status"] = DeliveryFailed
+	default:
+		updates["next_attempt_at"] = attempt.RetryAt
+	}
+
+	return d.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, DeliveryPending).
+		Updates(updates).Error
+}
+
+// PurgeWebhookDeliveries deletes finished deliveries older than the delivery log
+// retention.
+//
+// Returns:
+//   - int64: The number of deliveries deleted.
+//   - error: An error if the deletion fails.
+func (d *Database) PurgeWebhookDeliveries() (int64, error) {
+	result := d.db.Unscoped().
+		Where("status <> ? AND updated_at <= ?", DeliveryPending, time.Now().Add(-webhookLogRetention)).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
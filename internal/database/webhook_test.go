@@ -0,0 +1,39 @@
+package database
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dashboard-platform/template-service/internal/encryption"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookSecret verifies that sealed secrets open only with the key and the
+// webhook they were sealed for, and that plaintext secrets are still read.
+func TestWebhookSecret(t *testing.T) {
+	c, err := encryption.New(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	d := &Database{cipher: c}
+
+	webhook := models.Webhook{ID: uuid.New()}
+	webhook.EncryptedSecret, err = c.Seal([]byte("whsec_test"), webhook.ID[:])
+	require.NoError(t, err)
+
+	secret, err := d.webhookSecret(webhook)
+	require.NoError(t, err)
+	require.Equal(t, "whsec_test", secret)
+
+	moved := webhook
+	moved.ID = uuid.New()
+	_, err = d.webhookSecret(moved)
+	require.ErrorIs(t, err, encryption.ErrDecrypt)
+
+	_, err = (&Database{}).webhookSecret(webhook)
+	require.Error(t, err)
+
+	secret, err = d.webhookSecret(models.Webhook{ID: uuid.New(), Secret: "whsec_plain"})
+	require.NoError(t, err)
+	require.Equal(t, "whsec_plain", secret)
+}
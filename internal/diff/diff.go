@@ -0,0 +1,250 @@
+// Package diff computes line-based differences between two texts and formats
+// them as unified diffs. It is used to show upstream changes to forked templates.
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Memory grows with the square of the edit distance, so texts that are large or
+// differ a lot are not compared.
+const (
+	maxBytes = 1 << 20 // largest text Unified compares
+	maxEdits = 2000    // most lines Lines inserts and deletes
+)
+
+// ErrTooLarge is returned for texts that are too large or too different to compare.
+var ErrTooLarge = errors.New("texts are too large or too different to compare")
+
+// Op identifies the kind of an edit.
+type Op int
+
+const (
+	Equal  Op = iota // Line present in both texts.
+	Delete           // Line only present in the old text.
+	Insert           // Line only present in the new text.
+)
+
+// Edit is a single line of an edit script.
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Lines computes the shortest edit script turning a into b using Myers' algorithm.
+//
+// Parameters:
+//   - a: The old lines.
+//   - b: The new lines.
+//
+// Returns:
+//   - []Edit: The edit script, in order.
+//   - error: ErrTooLarge if the script would insert and delete more than maxEdits lines.
+func Lines(a, b []string) ([]Edit, error) {
+	n, m := len(a), len(b)
+	if n+m == 0 {
+		return nil, nil
+	}
+
+	maxD := min(n+m, maxEdits)
+	offset := maxD
+	v := make([]int, 2*maxD+2)
+
+	// trace[d] is the frontier before step d, on the diagonals -(d-1)..d-1 that
+	// step reads from.
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		if d == 0 {
+			trace = append(trace, nil)
+		} else {
+			trace = append(trace, slices.Clone(v[offset-d+1:offset+d]))
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, d), nil
+			}
+		}
+	}
+
+	return nil, ErrTooLarge
+}
+
+// backtrack walks the recorded frontier snapshots backwards to build the edit script.
+func backtrack(trace [][]int, a, b []string, d int) []Edit {
+	x, y := len(a), len(b)
+	var edits []Edit
+
+	for ; d > 0; d-- {
+		// The snapshot of step d starts at diagonal -(d-1).
+		offset := d - 1
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, Edit{Op: Equal, Text: a[x]})
+		}
+
+		if x == prevX {
+			y--
+			edits = append(edits, Edit{Op: Insert, Text: b[y]})
+		} else {
+			x--
+			edits = append(edits, Edit{Op: Delete, Text: a[x]})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, Edit{Op: Equal, Text: a[x]})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
+
+// Unified formats the difference between oldText and newText as a unified diff.
+// It returns an empty string when the texts are identical.
+//
+// Parameters:
+//   - oldName: The label of the old text in the header.
+//   - newName: The label of the new text in the header.
+//   - oldText: The old text.
+//   - newText: The new text.
+//   - context: The number of unchanged lines shown around each change.
+//
+// Returns:
+//   - string: The unified diff.
+//   - error: ErrTooLarge if a text exceeds maxBytes or the texts differ too much.
+func Unified(oldName, newName, oldText, newText string, context int) (string, error) {
+	if len(oldText) > maxBytes || len(newText) > maxBytes {
+		return "", ErrTooLarge
+	}
+
+	edits, err := Lines(splitLines(oldText), splitLines(newText))
+	if err != nil {
+		return "", err
+	}
+
+	changed := false
+	for _, e := range edits {
+		if e.Op != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return "", nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Line numbers (0-based) in the old and new text before each edit.
+	oldLine := make([]int, len(edits)+1)
+	newLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if e.Op != Insert {
+			oldLine[i+1]++
+		}
+		if e.Op != Delete {
+			newLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].Op == Equal {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].Op == Equal {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end = min(end+context, len(edits))
+				break
+			}
+			end = run
+		}
+
+		oldCount := oldLine[end] - oldLine[start]
+		newCount := newLine[end] - newLine[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldLine[start], oldCount), hunkRange(newLine[start], newCount))
+
+		for _, e := range edits[start:end] {
+			switch e.Op {
+			case Equal:
+				b.WriteString(" ")
+			case Delete:
+				b.WriteString("-")
+			case Insert:
+				b.WriteString("+")
+			}
+			b.WriteString(e.Text)
+			b.WriteString("\n")
+		}
+
+		i = end
+	}
+
+	return b.String(), nil
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
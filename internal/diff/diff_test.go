@@ -0,0 +1,90 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLines verifies that applying the edit script to a yields b, and that the
+// script is as short as possible.
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		changes int
+	}{
+		{name: "identical", a: "a b c", b: "a b c", changes: 0},
+		{name: "insert", a: "a c", b: "a b c", changes: 1},
+		{name: "delete", a: "a b c", b: "a c", changes: 1},
+		{name: "replace", a: "a b c", b: "a x c", changes: 2},
+		{name: "empty old", a: "", b: "x y", changes: 2},
+		{name: "empty new", a: "x y", b: "", changes: 2},
+		{name: "mixed", a: "a b c a b b a", b: "c b a b a c", changes: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+			edits, err := Lines(a, b)
+			require.NoError(t, err)
+
+			var gotA, gotB []string
+			changes := 0
+			for _, e := range edits {
+				if e.Op != Equal {
+					changes++
+				}
+				if e.Op != Insert {
+					gotA = append(gotA, e.Text)
+				}
+				if e.Op != Delete {
+					gotB = append(gotB, e.Text)
+				}
+			}
+			require.Equal(t, len(a), len(gotA))
+			require.Equal(t, len(b), len(gotB))
+			if len(a) > 0 {
+				require.Equal(t, a, gotA)
+			}
+			if len(b) > 0 {
+				require.Equal(t, b, gotB)
+			}
+			require.Equal(t, tt.changes, changes)
+		})
+	}
+}
+
+// TestLinesTooLarge verifies that scripts longer than maxEdits are refused.
+func TestLinesTooLarge(t *testing.T) {
+	lines := func(prefix string, n int) []string {
+		s := make([]string, n)
+		for i := range s {
+			s[i] = fmt.Sprint(prefix, i)
+		}
+		return s
+	}
+
+	edits, err := Lines(lines("a", maxEdits/2), lines("b", maxEdits/2))
+	require.NoError(t, err)
+	require.Len(t, edits, maxEdits)
+
+	_, err = Lines(lines("a", maxEdits/2+1), lines("b", maxEdits/2))
+	require.ErrorIs(t, err, ErrTooLarge)
+}
+
+// TestUnified verifies hunk headers and line prefixes.
+func TestUnified(t *testing.T) {
+	got, err := Unified("a", "b", "same\n", "same\n", 3)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	got, err = Unified("v1", "v2", "one\ntwo\nthree\n", "one\n2\nthree\n", 1)
+	require.NoError(t, err)
+	require.Equal(t, "--- v1\n+++ v2\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n", got)
+
+	_, err = Unified("v1", "v2", strings.Repeat("x", maxBytes+1), "", 3)
+	require.ErrorIs(t, err, ErrTooLarge)
+}
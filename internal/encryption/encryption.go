@@ -0,0 +1,92 @@
+// Package encryption seals sensitive values at rest with AES-256-GCM.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// keySize is the AES-256 key length in bytes.
+const keySize = 32
+
+// formatV1 prefixes sealed values: a version byte, the nonce, then the ciphertext.
+const formatV1 byte = 1
+
+// ErrDecrypt is returned when a sealed value cannot be opened.
+var ErrDecrypt = errors.New("cannot decrypt value")
+
+// Cipher seals and opens values with a single key.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New returns a Cipher for a 32-byte key.
+//
+// Parameters:
+//   - key: The AES-256 key.
+//
+// Returns:
+//   - *Cipher: The cipher.
+//   - error: An error if the key has the wrong length.
+func New(key []byte) (*Cipher, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", keySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// ParseKey decodes a base64 encoded 32-byte key, as stored in configuration.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not valid base64: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext with a random nonce. The additional data is
+// authenticated but not stored; the same value must be passed to Open.
+func (c *Cipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 1+len(nonce)+len(plaintext)+c.aead.Overhead())
+	out = append(out, formatV1)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts a value produced by Seal.
+func (c *Cipher) Open(sealed, additionalData []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < 1+nonceSize || sealed[0] != formatV1 {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[1:1+nonceSize], sealed[1+nonceSize:], additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
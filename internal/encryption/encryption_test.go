@@ -0,0 +1,51 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSealOpen verifies that sealed values open only with the same key and data.
+func TestSealOpen(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, keySize)
+	c, err := New(key)
+	require.NoError(t, err)
+
+	sealed, err := c.Seal([]byte(`{"name":"Ada"}`), []byte("history-1"))
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), "Ada")
+
+	plain, err := c.Open(sealed, []byte("history-1"))
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"Ada"}`, string(plain))
+
+	_, err = c.Open(sealed, []byte("history-2"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	other, err := New(bytes.Repeat([]byte{8}, keySize))
+	require.NoError(t, err)
+	_, err = other.Open(sealed, []byte("history-1"))
+	require.ErrorIs(t, err, ErrDecrypt)
+
+	_, err = c.Open(sealed[:5], nil)
+	require.ErrorIs(t, err, ErrDecrypt)
+}
+
+// TestKeys verifies key length and encoding checks.
+func TestKeys(t *testing.T) {
+	_, err := New([]byte("short"))
+	require.Error(t, err)
+
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, keySize)))
+	require.NoError(t, err)
+	require.Len(t, key, keySize)
+
+	_, err = ParseKey("not base64!")
+	require.Error(t, err)
+
+	_, err = ParseKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	require.Error(t, err)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) CreateAPIKey(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.CreateAPIKeyAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	key, plaintext, err := h.db.CreateAPIKey(scope, data)
+	if err != nil {
+		return fmt.Errorf("creating API key: %w", err)
+	}
+
+	// The plaintext key is only ever returned here.
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"api_key": key.ToDTO(),
+			"key":     plaintext,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetAPIKeys(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.db.GetAPIKeys(scope)
+	if err != nil {
+		return fmt.Errorf("retrieving API keys: %w", err)
+	}
+
+	dto := make([]models.APIKeyDTO, 0, len(keys))
+	for _, k := range keys {
+		dto = append(dto, k.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"api_keys": dto,
+		},
+	})
+}
+
+func (h *HTTPHandler) DeleteAPIKey(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	keyID := ctx.Params("id")
+	if err := h.db.DeleteAPIKey(scope, keyID); err != nil {
+		return fmt.Errorf("deleting API key %s: %w", keyID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/bundle"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) ExportTemplate(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	return h.exportBundle(ctx, templateID, "template-"+templateID+".zip")
+}
+
+func (h *HTTPHandler) ExportTemplates(ctx *fiber.Ctx) error {
+	return h.exportBundle(ctx, "", "templates.zip")
+}
+
+// exportBundle sends a bundle of one template, or of every template of the
+// workspace if templateID is empty, as an attachment.
+func (h *HTTPHandler) exportBundle(ctx *fiber.Ctx, templateID, filename string) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	templates, catalogs, err := h.db.ExportTemplates(scope, templateID)
+	if err != nil {
+		return fmt.Errorf("loading templates for export: %w", err)
+	}
+
+	b, err := bundle.New(templates, catalogs, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("assembling bundle: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf, b); err != nil {
+		return fmt.Errorf("writing bundle: %w", err)
+	}
+
+	ctx.Attachment(filename)
+	return ctx.Send(buf.Bytes())
+}
+
+func (h *HTTPHandler) ImportTemplates(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(strings.ToLower(ctx.Get(fiber.HeaderContentType)), "application/zip") {
+		return newRequestError(fiber.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Expected an application/zip bundle")
+	}
+
+	b, err := bundle.Read(ctx.Body())
+	if err != nil {
+		return badRequest("invalid_bundle", "Invalid bundle: %v", err)
+	}
+
+	result, err := h.db.ImportTemplates(scope, b)
+	if err != nil {
+		return fmt.Errorf("importing bundle: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"import": result,
+		},
+	})
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) CreateCatalog(ctx *fiber.Ctx) error {
+	var data models.CatalogAPI
+
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := h.db.CreateCatalog(scope, data)
+	if err != nil {
+		return fmt.Errorf("creating catalog: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"catalog": catalog.ToDTO(),
+		},
+	})
+}
+
+func (h *HTTPHandler) GetCatalogs(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	catalogs, err := h.db.GetCatalogs(scope)
+	if err != nil {
+		return fmt.Errorf("retrieving catalogs: %w", err)
+	}
+
+	dto := make([]models.CatalogDTO, 0, len(catalogs))
+	for _, c := range catalogs {
+		dto = append(dto, c.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"catalogs": dto,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetCatalogByID(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := h.db.GetCatalog(scope, ctx.Params("id"))
+	if err != nil {
+		return fmt.Errorf("retrieving catalog by ID: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"catalog": catalog.ToDTO(),
+		},
+	})
+}
+
+func (h *HTTPHandler) UpdateCatalog(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body models.CatalogAPI
+	if err := ctx.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+
+	if err := h.db.UpdateCatalog(scope, ctx.Params("id"), body); err != nil {
+		return fmt.Errorf("updating catalog: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *HTTPHandler) DeleteCatalog(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	catalogID := ctx.Params("id")
+	if err := h.db.DeleteCatalog(scope, catalogID); err != nil {
+		return fmt.Errorf("deleting catalog %s: %w", catalogID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) CloneTemplate(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.CloneTemplateAPI
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&data); err != nil {
+			return errInvalidBody
+		}
+	}
+
+	clone, err := h.db.CloneTemplate(scope, templateID, data)
+	if err != nil {
+		return fmt.Errorf("cloning template %s: %w", templateID, err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"template": clone.ToDTO(),
+		},
+	})
+}
+
+func (h *HTTPHandler) GetUpstream(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	upstream, err := h.db.GetUpstreamDiff(scope, templateID)
+	if err != nil {
+		return fmt.Errorf("comparing template %s with upstream: %w", templateID, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"upstream": upstream,
+		},
+	})
+}
+
+func (h *HTTPHandler) PullUpstream(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	version, err := h.db.PullUpstream(scope, templateID)
+	if err != nil {
+		return fmt.Errorf("pulling upstream changes into template %s: %w", templateID, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"version": models.TemplateVersionDTO{
+				Version: version.Version,
+				Content: version.Content,
+			},
+		},
+	})
+}
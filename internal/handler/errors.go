@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// mimeProblemJSON is the media type of RFC 7807 problem documents.
+const mimeProblemJSON = "application/problem+json"
+
+// requestError is a client error detected by a handler itself, such as an
+// unparsable body or query parameter.
+type requestError struct {
+	status  int
+	code    string
+	message string
+	details map[string]any
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// newRequestError returns an error with an HTTP status and a machine-readable code.
+func newRequestError(status int, code, format string, args ...any) *requestError {
+	return &requestError{status: status, code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// badRequest returns a 400 error with a machine-readable code.
+func badRequest(code, format string, args ...any) *requestError {
+	return newRequestError(fiber.StatusBadRequest, code, format, args...)
+}
+
+var (
+	errInvalidBody       = badRequest("invalid_body", "Invalid request body")
+	errTemplateIDMissing = badRequest("template_id_missing", "Template ID is required")
+)
+
+// ErrorHandler writes the errors returned by handlers and middleware as problem
+// documents. Domain errors of the database map to their HTTP status; any other
+// error is reported as an internal error without exposing its message.
+//
+// Parameters:
+//   - ctx: The request context.
+//   - err: The error returned by the handler chain.
+//
+// Returns:
+//   - error: An error if the response cannot be written.
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	problem := models.ErrorDTO{
+		Code:    "internal_error",
+		Message: "Internal server error",
+	}
+	status := fiber.StatusInternalServerError
+
+	var reqErr *requestError
+	var fiberErr *fiber.Error
+	if errors.As(err, &reqErr) {
+		status = reqErr.status
+		problem.Code, problem.Message, problem.Details = reqErr.code, reqErr.message, reqErr.details
+	} else if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+		problem.Code, problem.Message = statusCode(status), fiberErr.Message
+	} else if dbErr, ok := database.AsError(err); ok {
+		status = kindStatus(dbErr.Kind)
+		problem.Code, problem.Message, problem.Details = dbErr.Code, dbErr.Message, dbErr.Details
+	}
+
+	problem.RequestID, _ = ctx.Locals("requestid").(string)
+
+	return ctx.Status(status).JSON(models.Problem{
+		Type:     "about:blank",
+		Title:    utils.StatusMessage(status),
+		Status:   status,
+		Detail:   problem.Message,
+		Instance: ctx.OriginalURL(),
+		Error:    problem,
+	}, mimeProblemJSON)
+}
+
+// kindStatus returns the HTTP status of a domain error kind.
+func kindStatus(kind database.ErrorKind) int {
+	switch kind {
+	case database.KindNotFound:
+		return fiber.StatusNotFound
+	case database.KindForbidden:
+		return fiber.StatusForbidden
+	case database.KindConflict:
+		return fiber.StatusConflict
+	case database.KindValidation:
+		return fiber.StatusBadRequest
+	case database.KindPrecondition:
+		return fiber.StatusPreconditionFailed
+	case database.KindUnprocessable:
+		return fiber.StatusUnprocessableEntity
+	}
+	return fiber.StatusInternalServerError
+}
+
+// statusCode derives an error code from an HTTP status, e.g. "too_many_requests".
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(utils.StatusMessage(status)), " ", "_")
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestErrorHandler verifies the status, code and message of problem documents.
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{
+			name:    "wrapped domain error",
+			err:     fmt.Errorf("deleting template: %w", database.ErrTemplateNotFound),
+			status:  fiber.StatusNotFound,
+			code:    "template_not_found",
+			message: database.ErrTemplateNotFound.Message,
+		},
+		{
+			name:    "insufficient role",
+			err:     database.ErrInsufficientRole,
+			status:  fiber.StatusForbidden,
+			code:    "insufficient_role",
+			message: database.ErrInsufficientRole.Message,
+		},
+		{
+			name:    "precondition failed",
+			err:     database.ErrPreconditionFailed,
+			status:  fiber.StatusPreconditionFailed,
+			code:    "precondition_failed",
+			message: database.ErrPreconditionFailed.Message,
+		},
+		{
+			name:    "unprocessable",
+			err:     fmt.Errorf("patching template: %w", &database.Error{Kind: database.KindUnprocessable, Code: "invalid_patch", Message: "invalid merge patch"}),
+			status:  fiber.StatusUnprocessableEntity,
+			code:    "invalid_patch",
+			message: "invalid merge patch",
+		},
+		{
+			name:    "record not found",
+			err:     fmt.Errorf("retrieving catalog: %w", gorm.ErrRecordNotFound),
+			status:  fiber.StatusNotFound,
+			code:    "not_found",
+			message: "resource not found",
+		},
+		{
+			name:    "request error",
+			err:     errInvalidBody,
+			status:  fiber.StatusBadRequest,
+			code:    "invalid_body",
+			message: "Invalid request body",
+		},
+		{
+			name:    "removed endpoint",
+			err:     errHistoryWriteGone,
+			status:  fiber.StatusGone,
+			code:    "endpoint_removed",
+			message: errHistoryWriteGone.message,
+		},
+		{
+			name:    "fiber error",
+			err:     fiber.ErrTooManyRequests,
+			status:  fiber.StatusTooManyRequests,
+			code:    "too_many_requests",
+			message: "Too Many Requests",
+		},
+		{
+			name:    "internal error",
+			err:     errors.New("connection reset by peer"),
+			status:  fiber.StatusInternalServerError,
+			code:    "internal_error",
+			message: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", func(*fiber.Ctx) error { return tt.err })
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode)
+			require.Equal(t, mimeProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+
+			var problem models.Problem
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+			require.Equal(t, tt.status, problem.Status)
+			require.Equal(t, tt.code, problem.Error.Code)
+			require.Equal(t, tt.message, problem.Error.Message)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) FavoriteTemplate(ctx *fiber.Ctx) error {
+	return h.setFavorite(ctx, true)
+}
+
+func (h *HTTPHandler) UnfavoriteTemplate(ctx *fiber.Ctx) error {
+	return h.setFavorite(ctx, false)
+}
+
+func (h *HTTPHandler) setFavorite(ctx *fiber.Ctx, favorite bool) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.db.SetFavorite(scope, templateID, favorite); err != nil {
+		return fmt.Errorf("updating favorite for template %s: %w", templateID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *HTTPHandler) GetFavorites(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	favorites, err := h.db.GetFavorites(scope)
+	if err != nil {
+		return fmt.Errorf("retrieving favorites: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"templates": favorites,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetRecentTemplates(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	recent, frequent, err := h.db.GetRecentTemplates(scope, ctx.QueryInt("days", 0), ctx.QueryInt("limit", 0))
+	if err != nil {
+		return fmt.Errorf("retrieving recent templates: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"recent":   recent,
+			"frequent": frequent,
+		},
+	})
+}
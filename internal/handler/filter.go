@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// templateFilter reads the listing filters of GET /templates from the query string.
+// Time bounds use RFC 3339. The returned errors are safe to show to clients.
+func templateFilter(ctx *fiber.Ctx) (database.TemplateFilter, error) {
+	filter := database.TemplateFilter{
+		Type:     ctx.Query("type"),
+		Category: ctx.Query("category"),
+		Tag:      ctx.Query("tag"),
+		Sort:     ctx.Query("sort"),
+		Cursor:   ctx.Query("cursor"),
+		Limit:    ctx.QueryInt("limit", 0),
+	}
+
+	if v := ctx.Query("folder"); v != "" {
+		folderID, err := uuid.Parse(v)
+		if err != nil {
+			return filter, badRequest("invalid_query", "invalid folder %q", v)
+		}
+		filter.FolderID = &folderID
+	}
+
+	if v := ctx.Query("is_public"); v != "" {
+		public, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, badRequest("invalid_query", "invalid is_public %q", v)
+		}
+		filter.IsPublic = &public
+	}
+
+	if err := timeParams(ctx, map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+		"updated_after":  &filter.UpdatedAfter,
+		"updated_before": &filter.UpdatedBefore,
+	}); err != nil {
+		return filter, err
+	}
+
+	if err := filter.Normalize(); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// historyFilter reads the history filters from the query string: template_id,
+// version, from and to (RFC 3339), cursor and limit.
+func historyFilter(ctx *fiber.Ctx) (database.HistoryFilter, error) {
+	filter := database.HistoryFilter{
+		Version: ctx.QueryInt("version", 0),
+		Cursor:  ctx.Query("cursor"),
+		Limit:   ctx.QueryInt("limit", 0),
+	}
+
+	if v := ctx.Query("template_id"); v != "" {
+		templateID, err := uuid.Parse(v)
+		if err != nil {
+			return filter, badRequest("invalid_query", "invalid template_id %q", v)
+		}
+		filter.TemplateID = &templateID
+	}
+
+	if err := timeParams(ctx, map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	}); err != nil {
+		return filter, err
+	}
+
+	filter.Normalize()
+	return filter, nil
+}
+
+// timeParams parses the RFC 3339 query parameters present in the request into
+// their destinations.
+func timeParams(ctx *fiber.Ctx, params map[string]**time.Time) error {
+	for param, dst := range params {
+		v := ctx.Query(param)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return badRequest("invalid_query", "invalid %s %q, expected RFC 3339", param, v)
+		}
+		*dst = &t
+	}
+
+	return nil
+}
+
+// statsFilter reads the statistics range from the query string: bucket, from and
+// to (RFC 3339) and limit.
+func statsFilter(ctx *fiber.Ctx) (database.StatsFilter, error) {
+	filter := database.StatsFilter{
+		Bucket: ctx.Query("bucket"),
+		Limit:  ctx.QueryInt("limit", 0),
+	}
+
+	var from, to *time.Time
+	if err := timeParams(ctx, map[string]**time.Time{
+		"from": &from,
+		"to":   &to,
+	}); err != nil {
+		return filter, err
+	}
+	if from != nil {
+		filter.From = *from
+	}
+	if to != nil {
+		filter.To = *to
+	}
+
+	if err := filter.Normalize(); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) CreateFolder(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.FolderAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	folder, err := h.db.CreateFolder(scope, data)
+	if err != nil {
+		return fmt.Errorf("creating folder: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"folder": folder.ToDTO(),
+		},
+	})
+}
+
+func (h *HTTPHandler) GetFolders(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	folders, err := h.db.GetFolders(scope)
+	if err != nil {
+		return fmt.Errorf("retrieving folders: %w", err)
+	}
+
+	dto := make([]models.FolderDTO, 0, len(folders))
+	for _, f := range folders {
+		dto = append(dto, f.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"folders": dto,
+		},
+	})
+}
+
+func (h *HTTPHandler) UpdateFolder(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body models.FolderAPI
+	if err := ctx.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+
+	if err := h.db.UpdateFolder(scope, ctx.Params("id"), body); err != nil {
+		return fmt.Errorf("updating folder: %w", err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *HTTPHandler) DeleteFolder(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	folderID := ctx.Params("id")
+	if err := h.db.DeleteFolder(scope, folderID); err != nil {
+		return fmt.Errorf("deleting folder %s: %w", folderID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *HTTPHandler) MoveTemplate(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.MoveTemplateAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	if err := h.db.MoveTemplate(scope, templateID, data.FolderID); err != nil {
+		return fmt.Errorf("moving template %s: %w", templateID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) PublishTemplate(ctx *fiber.Ctx) error {
+	return h.setVisibility(ctx, true)
+}
+
+func (h *HTTPHandler) UnpublishTemplate(ctx *fiber.Ctx) error {
+	return h.setVisibility(ctx, false)
+}
+
+func (h *HTTPHandler) setVisibility(ctx *fiber.Ctx, public bool) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.db.SetTemplateVisibility(scope, templateID, public); err != nil {
+		return fmt.Errorf("changing visibility of template %s: %w", templateID, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"id":        templateID,
+			"is_public": public,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetGallery(ctx *fiber.Ctx) error {
+	filter := database.GalleryFilter{
+		Category: ctx.Query("category"),
+		Type:     ctx.Query("type"),
+		Page:     ctx.QueryInt("page", 1),
+		Limit:    ctx.QueryInt("limit", 0),
+	}
+	filter.Normalize()
+
+	templates, total, err := h.db.GetPublicTemplates(filter)
+	if err != nil {
+		return fmt.Errorf("retrieving gallery templates: %w", err)
+	}
+
+	dto := make([]models.TemplateDTO, 0, len(templates))
+	for _, t := range templates {
+		dto = append(dto, t.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"templates": dto,
+			"page":      filter.Page,
+			"limit":     filter.Limit,
+			"total":     total,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetGalleryTemplate(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	template, err := h.db.GetPublicTemplateByID(templateID)
+	if err != nil {
+		return fmt.Errorf("retrieving public template by ID: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"template": template.ToDTO(),
+		},
+	})
+}
@@ -3,31 +3,41 @@
 package handler
 
 import (
-	"github.com/aymerick/raymond"
+	"fmt"
+
 	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/internal/encryption"
 	"github.com/dashboard-platform/template-service/models"
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
 // HTTPHandler represents the HTTP handlers for the authentication service.
 // It includes methods for health checks, user registration, login, and retrieving user details.
 type HTTPHandler struct {
-	db *database.Database
+	db   *database.Database
+	opts Options
+}
+
+// Options holds the settings handlers need beyond the database.
+type Options struct {
+	ShareLinkSecret []byte                  // Signs share link tokens; share links are disabled if empty.
+	HistoryCipher   *encryption.Cipher      // Encrypts render values in the history, if set.
+	Retention       database.RetentionRules // Global history retention rules.
 }
 
 // New creates a new instance of HTTPHandler.
 //
 // Parameters:
-//   - authService: The authentication service implementation.
-//   - jwtObj: The JWT utility object.
+//   - db: The database the handlers operate on.
+//   - opts: Additional handler settings.
 //
 // Returns:
 //   - HTTPHandler: A new instance of the HTTPHandler.
-func New(db *database.Database) HTTPHandler {
+func New(db *database.Database, opts Options) HTTPHandler {
 	return HTTPHandler{
-		db: db,
+		db:   db,
+		opts: opts,
 	}
 }
 
@@ -48,34 +58,17 @@ func (h *HTTPHandler) CreateTemplate(ctx *fiber.Ctx) error {
 	var data models.CreateTemplateAPI
 
 	if err := ctx.BodyParser(&data); err != nil {
-		log.Error().Err(err).Msg("error reading/parsing HTTP request body data")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
-	}
-
-	userIDStr := ctx.Get("X-User-ID")
-	if userIDStr == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
+		return errInvalidBody
 	}
 
-	userID, err := uuid.Parse(userIDStr)
+	scope, err := requestScope(ctx)
 	if err != nil {
-		log.Error().Err(err).Msg("error parsing X-User-ID header")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid X-User-ID header",
-		})
+		return err
 	}
 
-	id, err := h.db.CreateTemplate(userID, data)
+	id, err := h.db.CreateTemplate(scope, data)
 	if err != nil {
-		log.Error().Err(err).Msg("error creating template")
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create template",
-		})
+		return fmt.Errorf("creating template: %w", err)
 	}
 
 	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
@@ -88,58 +81,79 @@ func (h *HTTPHandler) CreateTemplate(ctx *fiber.Ctx) error {
 }
 
 func (h *HTTPHandler) GetTemplates(ctx *fiber.Ctx) error {
-	userID := ctx.Get("X-User-ID")
-	if userID == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
 	}
 
-	templates, err := h.db.GetTemplates(userID)
+	filter, err := templateFilter(ctx)
 	if err != nil {
-		log.Error().Err(err).Msg("error retrieving templates")
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve templates",
-		})
+		return err
 	}
 
-	var dto []models.TemplateDTO
-	for _, t := range templates {
-		dto = append(dto, t.ToDTO())
+	templates, next, err := h.db.ListTemplates(scope, filter)
+	if err != nil {
+		return fmt.Errorf("retrieving templates: %w", err)
+	}
+
+	data := fiber.Map{
+		"templates":   templates,
+		"next_cursor": nil,
+	}
+	if next != "" {
+		data["next_cursor"] = next
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data:  data,
+	})
+}
+
+func (h *HTTPHandler) SearchTemplates(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	q := ctx.Query("q")
+	if q == "" {
+		return badRequest("query_missing", "Search query is required")
+	}
+
+	results, err := h.db.SearchTemplates(scope, q, ctx.QueryInt("limit", 0))
+	if err != nil {
+		return fmt.Errorf("searching templates: %w", err)
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(models.Response{
 		Error: false,
 		Data: fiber.Map{
-			"templates": dto,
+			"results": results,
 		},
 	})
 }
 
 func (h *HTTPHandler) GetTemplateByID(ctx *fiber.Ctx) error {
-	userID := ctx.Get("X-User-ID")
-	if userID == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
 	}
 
 	templateID := ctx.Params("id")
 	if templateID == "" {
-		log.Error().Msg("template ID is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Template ID is required",
-		})
+		return errTemplateIDMissing
 	}
 
-	template, err := h.db.GetTemplateByID(userID, templateID)
+	template, err := h.db.GetTemplateByID(scope, templateID)
 	if err != nil {
-		log.Error().Err(err).Msg("error retrieving template by ID")
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve template",
-		})
+		return fmt.Errorf("retrieving template by ID: %w", err)
+	}
+
+	etag := database.TemplateETag(template)
+	ctx.Set(fiber.HeaderETag, etag)
+	if ifNoneMatch := ctx.Get(fiber.HeaderIfNoneMatch); ifNoneMatch != "" && database.MatchETag(ifNoneMatch, etag, true) {
+		return ctx.SendStatus(fiber.StatusNotModified)
 	}
 
 	dto := template.ToDTO()
@@ -155,53 +169,31 @@ func (h *HTTPHandler) GetTemplateByID(ctx *fiber.Ctx) error {
 func (h *HTTPHandler) PreviewTemplate(ctx *fiber.Ctx) error {
 	templateID := ctx.Params("id")
 	if templateID == "" {
-		log.Error().Msg("template ID is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Template ID is required",
-		})
+		return errTemplateIDMissing
 	}
 
-	userID := ctx.Get("X-User-ID")
-	if userID == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
 	}
 
-	var req struct {
-		Values map[string]interface{} `json:"values"`
-	}
+	var req models.RenderAPI
 	if err := ctx.BodyParser(&req); err != nil {
-		log.Error().Err(err).Msg("error reading/parsing HTTP request body data")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return errInvalidBody
 	}
 
-	template, err := h.db.GetTemplateByID(userID, templateID)
+	template, err := h.db.GetTemplateByID(scope, templateID)
 	if err != nil {
-		log.Error().Err(err).Msg("error retrieving template by ID")
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to retrieve template",
-		})
+		return fmt.Errorf("retrieving template by ID: %w", err)
 	}
 
 	if len(template.Versions) == 0 {
-		log.Error().Msg("template has no versions")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Template has no versions",
-		})
+		return newRequestError(fiber.StatusConflict, "no_versions", "Template has no versions")
 	}
 
-	content := template.Versions[0].Content
-
-	result, err := raymond.Render(content, req.Values)
+	result, err := h.renderVersion(ctx, template, template.Versions[0], req.Values, req.Locale)
 	if err != nil {
-		log.Error().Err(err).Msg("error rendering template")
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to render template",
-		})
+		return fmt.Errorf("rendering template: %w", err)
 	}
 
 	return ctx.Status(fiber.StatusOK).JSON(models.Response{
@@ -215,137 +207,42 @@ func (h *HTTPHandler) PreviewTemplate(ctx *fiber.Ctx) error {
 func (h *HTTPHandler) UpdateTemplate(ctx *fiber.Ctx) error {
 	templateID := ctx.Params("id")
 	if templateID == "" {
-		log.Error().Msg("template ID is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Template ID is required",
-		})
+		return errTemplateIDMissing
 	}
 
-	userID := ctx.Get("X-User-ID")
-	if userID == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
 	}
 
 	var body models.CreateTemplateAPI
 	if err := ctx.BodyParser(&body); err != nil {
-		log.Error().Msg("error parsing HTTP body request")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid body passed",
-		})
+		return errInvalidBody
 	}
 
-	if err := h.db.UpdateTemplate(userID, templateID, body); err != nil {
-		log.Error().Msgf("error updating template: %v", err)
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "unexpected error occurred while updating template",
-		})
+	etag, err := h.db.UpdateTemplate(scope, templateID, body, ctx.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fmt.Errorf("updating template: %w", err)
 	}
 
+	ctx.Set(fiber.HeaderETag, etag)
 	return ctx.SendStatus(fiber.StatusOK)
 }
 
 func (h *HTTPHandler) DeleteTemplate(ctx *fiber.Ctx) error {
 	templateID := ctx.Params("id")
 	if templateID == "" {
-		log.Error().Msg("template ID is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Template ID is required",
-		})
-	}
-
-	userID := ctx.Get("X-User-ID")
-	if userID == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
+		return errTemplateIDMissing
 	}
 
-	if err := h.db.DeleteTemplate(userID, templateID); err != nil {
-		log.Error().Msgf("error deleting template %s: %v", templateID, err)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "error deleting template",
-		})
-	}
-
-	return ctx.SendStatus(fiber.StatusOK)
-}
-
-func (h *HTTPHandler) CreateHistory(ctx *fiber.Ctx) error {
-	var data models.TemplateDTO
-
-	if err := ctx.BodyParser(&data); err != nil {
-		log.Error().Err(err).Msg("failed to parse request body")
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
-	}
-
-	userIDStr := ctx.Get("X-User-ID")
-	if userIDStr == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		log.Error().Err(err).Msg("error parsing X-User-ID header")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid X-User-ID header",
-		})
-	}
-
-	if err := h.db.CreateHistory(data, userID); err != nil {
-		log.Error().Err(err).Msgf("failed to create history for %s", data.ID)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "unexpected error",
-		})
-	}
-
-	log.Info().Str("user_id", userIDStr).Str("template_id", data.ID).Msg("template history saved")
-
-	return ctx.Status(fiber.StatusOK).JSON(models.Response{
-		Error: false,
-		Data:  nil,
-	})
-}
-
-func (h *HTTPHandler) GetHistory(ctx *fiber.Ctx) error {
-	userIDStr := ctx.Get("X-User-ID")
-	if userIDStr == "" {
-		log.Error().Msg("X-User-ID header is missing")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "X-User-ID header is required",
-		})
-	}
-
-	userID, err := uuid.Parse(userIDStr)
+	scope, err := requestScope(ctx)
 	if err != nil {
-		log.Error().Err(err).Msg("error parsing X-User-ID header")
-		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid X-User-ID header",
-		})
+		return err
 	}
 
-	log.Info().Msgf("user-id: %v", userID)
-
-	data, err := h.db.GetHistory(userID)
-	if err != nil {
-		log.Error().Err(err).Msgf("failed to get history for %s", userIDStr)
-		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "unexpected error",
-		})
+	if err := h.db.DeleteTemplate(scope, templateID, ctx.Get(fiber.HeaderIfMatch)); err != nil {
+		return fmt.Errorf("deleting template %s: %w", templateID, err)
 	}
 
-	return ctx.Status(fiber.StatusOK).JSON(models.Response{
-		Error: false,
-		Data: fiber.Map{
-			"history": data,
-		},
-	})
+	return ctx.SendStatus(fiber.StatusOK)
 }
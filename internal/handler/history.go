@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// errHistoryWriteGone answers the removed POST /templates/history. Renders are
+// recorded by the render endpoints themselves, so clients no longer report them.
+var errHistoryWriteGone = &requestError{
+	status:  fiber.StatusGone,
+	code:    "endpoint_removed",
+	message: "History entries are recorded when a template is rendered; use POST /templates/{id}/preview or POST /shared/{token}/render",
+	details: map[string]any{
+		"successors": []string{"/templates/{id}/preview", "/shared/{token}/render"},
+	},
+}
+
+func (h *HTTPHandler) CreateHistory(ctx *fiber.Ctx) error {
+	return errHistoryWriteGone
+}
+
+func (h *HTTPHandler) GetHistory(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter, err := historyFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, next, err := h.db.GetHistory(scope, filter)
+	if err != nil {
+		return fmt.Errorf("retrieving history: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data:  h.historyPage(data, next),
+	})
+}
+
+func (h *HTTPHandler) GetTemplateHistory(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter, err := historyFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, next, err := h.db.GetTemplateHistory(scope, templateID, filter)
+	if err != nil {
+		return fmt.Errorf("retrieving history of template %s: %w", templateID, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data:  h.historyPage(data, next),
+	})
+}
+
+// historyPage builds the response data of a page of history entries.
+func (h *HTTPHandler) historyPage(entries []models.TemplateHistory, next string) fiber.Map {
+	dto := make([]models.HistoryDTO, 0, len(entries))
+	for _, entry := range entries {
+		dto = append(dto, h.historyDTO(entry))
+	}
+
+	data := fiber.Map{
+		"history":     dto,
+		"next_cursor": nil,
+	}
+	if next != "" {
+		data["next_cursor"] = next
+	}
+
+	return data
+}
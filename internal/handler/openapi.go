@@ -0,0 +1,10 @@
+package handler
+
+import (
+	"github.com/dashboard-platform/template-service/internal/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) GetOpenAPI(ctx *fiber.Ctx) error {
+	return ctx.JSON(openapi.Spec())
+}
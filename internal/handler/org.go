@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) CreateOrganization(ctx *fiber.Ctx) error {
+	var data models.CreateOrganizationAPI
+
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	org, err := h.db.CreateOrganization(scope.UserID, data)
+	if err != nil {
+		return fmt.Errorf("creating organization: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"organization": models.OrganizationDTO{
+				ID:        org.ID.String(),
+				Name:      org.Name,
+				Role:      string(database.OrgRoleOwner),
+				CreatedAt: org.CreatedAt,
+			},
+		},
+	})
+}
+
+func (h *HTTPHandler) GetOrganizations(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	orgs, err := h.db.GetOrganizations(scope.UserID)
+	if err != nil {
+		return fmt.Errorf("retrieving organizations: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"organizations": orgs,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetOrgMembers(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	orgID := ctx.Params("id")
+	members, err := h.db.GetOrgMembers(scope.UserID, orgID)
+	if err != nil {
+		return fmt.Errorf("retrieving members of organization %s: %w", orgID, err)
+	}
+
+	dto := make([]models.OrgMemberDTO, 0, len(members))
+	for _, m := range members {
+		dto = append(dto, m.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"members": dto,
+		},
+	})
+}
+
+func (h *HTTPHandler) SetOrgMember(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.OrgMemberAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	orgID := ctx.Params("id")
+	member, err := h.db.SetOrgMember(scope.UserID, orgID, data)
+	if err != nil {
+		return fmt.Errorf("updating members of organization %s: %w", orgID, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"member": member.ToDTO(),
+		},
+	})
+}
+
+func (h *HTTPHandler) RemoveOrgMember(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	orgID := ctx.Params("id")
+	if err := h.db.RemoveOrgMember(scope.UserID, orgID, ctx.Params("userId")); err != nil {
+		return fmt.Errorf("removing member from organization %s: %w", orgID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/i18n"
+	"github.com/dashboard-platform/template-service/internal/render"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// History client types.
+const (
+	clientUser      = "user"
+	clientAPIKey    = "api_key"
+	clientShareLink = "share_link"
+)
+
+// renderVersion renders a template version with the given values and records the
+// render in the history. An empty locale falls back to the request's
+// Accept-Language header.
+func (h *HTTPHandler) renderVersion(ctx *fiber.Ctx, template models.Template, version models.TemplateVersion, values map[string]any, locale string) (string, error) {
+	if locale == "" {
+		locale = i18n.PreferredLocale(ctx.Get(fiber.HeaderAcceptLanguage))
+	}
+
+	snapshot, err := h.db.RenderSnapshot(template, locale)
+	if err != nil {
+		return "", fmt.Errorf("loading template catalog: %w", err)
+	}
+
+	start := time.Now()
+	output, err := renderSnapshot(version.Content, values, snapshot)
+	if err != nil {
+		return "", err
+	}
+	duration := time.Since(start)
+
+	if err := h.recordRender(ctx, template, version, values, snapshot, output, duration); err != nil {
+		// The output is still returned; a missing history entry is logged for follow-up.
+		log.Error().Err(err).Str("template_id", template.ID.String()).Msg("error recording render history")
+	}
+
+	return output, nil
+}
+
+// renderSnapshot renders content with the translations captured in snapshot.
+func renderSnapshot(content string, values map[string]any, snapshot models.RenderSnapshot) (string, error) {
+	var translator *i18n.Translator
+	if snapshot.Messages != nil {
+		translator = i18n.NewTranslator(snapshot.Messages, snapshot.Locale, snapshot.DefaultLocale)
+	}
+
+	return render.Render(content, values, render.Options{Translator: translator})
+}
+
+// outputHash fingerprints rendered output.
+func outputHash(output string) string {
+	sum := sha256.Sum256([]byte(output))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordRender stores a history entry with the render inputs, an output
+// fingerprint and the identity of the client. Values are encrypted when a history
+// cipher is configured.
+func (h *HTTPHandler) recordRender(ctx *fiber.Ctx, template models.Template, version models.TemplateVersion, values map[string]any, snapshot models.RenderSnapshot, output string, duration time.Duration) error {
+	entry := models.TemplateHistory{
+		ID:           uuid.New(),
+		TemplateID:   template.ID,
+		Version:      version.Version,
+		TemplateName: template.Name,
+		Format:       template.Type,
+		OutputHash:   outputHash(output),
+		OutputSize:   len(output),
+		DurationMS:   duration.Milliseconds(),
+		ClientIP:     ctx.IP(),
+		UserAgent:    ctx.Get(fiber.HeaderUserAgent),
+	}
+
+	switch {
+	case ctx.Locals("share_link") != nil:
+		entry.ClientType = clientShareLink
+	case ctx.Locals("api_key") != nil:
+		apiKey := ctx.Locals("api_key").(models.APIKey)
+		entry.ClientType = clientAPIKey
+		entry.APIKeyID = &apiKey.ID
+		entry.UserID = apiKey.UserID
+	case ctx.Locals("user_id") != nil:
+		entry.ClientType = clientUser
+		entry.UserID, _ = uuid.Parse(ctx.Locals("user_id").(string))
+	}
+
+	var err error
+	if entry.Snapshot, err = json.Marshal(snapshot); err != nil {
+		return err
+	}
+
+	rawValues, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	if h.opts.HistoryCipher != nil {
+		// Bind the ciphertext to its entry so it cannot be swapped between rows.
+		if entry.EncryptedValues, err = h.opts.HistoryCipher.Seal(rawValues, entry.ID[:]); err != nil {
+			return err
+		}
+	} else {
+		entry.Values = rawValues
+	}
+
+	return h.db.CreateHistory(template, entry)
+}
+
+// historyDTO converts a history entry, decrypting its values when possible.
+func (h *HTTPHandler) historyDTO(entry models.TemplateHistory) models.HistoryDTO {
+	dto := entry.ToDTO()
+
+	if entry.EncryptedValues != nil && h.opts.HistoryCipher != nil {
+		values, err := h.opts.HistoryCipher.Open(entry.EncryptedValues, entry.ID[:])
+		if err != nil {
+			log.Error().Err(err).Str("history_id", entry.ID.String()).Msg("error decrypting history values")
+			return dto
+		}
+		dto.Values = values
+	}
+
+	return dto
+}
+
+func (h *HTTPHandler) RerenderHistory(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	historyID := ctx.Params("id")
+	entry, version, err := h.db.GetHistoryEntry(scope, historyID)
+	if err != nil {
+		return fmt.Errorf("retrieving history entry %s: %w", historyID, err)
+	}
+
+	if entry.OutputHash == "" {
+		return newRequestError(fiber.StatusConflict, "unverifiable",
+			"History entry predates output fingerprints and cannot be verified")
+	}
+
+	rawValues := []byte(entry.Values)
+	if entry.EncryptedValues != nil {
+		if h.opts.HistoryCipher == nil {
+			return newRequestError(fiber.StatusConflict, "values_unavailable",
+				"History values are encrypted and no key is configured")
+		}
+		if rawValues, err = h.opts.HistoryCipher.Open(entry.EncryptedValues, entry.ID[:]); err != nil {
+			log.Error().Err(err).Msgf("error decrypting history entry %s", historyID)
+			return newRequestError(fiber.StatusConflict, "values_unavailable", "History values cannot be decrypted")
+		}
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(rawValues, &values); err != nil {
+		log.Error().Err(err).Msgf("error decoding values of history entry %s", historyID)
+		return newRequestError(fiber.StatusConflict, "values_unavailable", "History entry has no usable values")
+	}
+
+	// Entries recorded before snapshots existed render without translations.
+	var snapshot models.RenderSnapshot
+	if entry.Snapshot != nil {
+		if err := json.Unmarshal(entry.Snapshot, &snapshot); err != nil {
+			log.Error().Err(err).Msgf("error decoding snapshot of history entry %s", historyID)
+			return newRequestError(fiber.StatusConflict, "snapshot_unavailable", "History entry has no usable snapshot")
+		}
+	}
+
+	output, err := renderSnapshot(version.Content, values, snapshot)
+	if err != nil {
+		return fmt.Errorf("re-rendering history entry %s: %w", historyID, err)
+	}
+
+	// A document that does not match its fingerprint is not the original.
+	hash := outputHash(output)
+	if hash != entry.OutputHash {
+		log.Warn().Str("history_id", historyID).Str("expected", entry.OutputHash).Str("actual", hash).
+			Msg("re-rendered output does not match the recorded hash")
+		err := newRequestError(fiber.StatusConflict, "hash_mismatch", "Re-rendered output does not match the recorded hash")
+		err.details = map[string]any{
+			"expected_hash": entry.OutputHash,
+			"actual_hash":   hash,
+		}
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"output":      output,
+			"output_hash": hash,
+			"history":     h.historyDTO(entry),
+		},
+	})
+}
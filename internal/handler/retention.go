@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) GetRetentionPolicy(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	policy, err := h.db.GetRetentionPolicy(scope, h.opts.Retention)
+	if err != nil {
+		return fmt.Errorf("retrieving retention policy: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"policy": policy,
+		},
+	})
+}
+
+func (h *HTTPHandler) SetRetentionPolicy(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body models.RetentionPolicyAPI
+	if err := ctx.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+
+	policy, err := h.db.SetRetentionPolicy(scope, body)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"policy": policy,
+		},
+	})
+}
+
+func (h *HTTPHandler) DeleteRetentionPolicy(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.db.DeleteRetentionPolicy(scope); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *HTTPHandler) PreviewHistoryPurge(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := h.db.PreviewPurge(scope, h.opts.Retention)
+	if err != nil {
+		return fmt.Errorf("previewing history purge: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"purge": report,
+		},
+	})
+}
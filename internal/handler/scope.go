@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// requestScope builds the database scope of a request from the caller identity
+// stored by the authentication middleware and the optional X-Org-ID header
+// selecting an organization workspace. API keys are bound to the workspace and
+// templates they were issued for. The returned errors are safe to show to clients.
+func requestScope(ctx *fiber.Ctx) (database.Scope, error) {
+	userIDStr, _ := ctx.Locals("user_id").(string)
+	if userIDStr == "" {
+		if _, ok := ctx.Locals("api_key").(models.APIKey); ok {
+			return database.Scope{}, newRequestError(fiber.StatusForbidden, "api_key_not_allowed", "API keys are not accepted for this endpoint")
+		}
+		return database.Scope{}, newRequestError(fiber.StatusUnauthorized, "unauthenticated", "authentication required")
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return database.Scope{}, newRequestError(fiber.StatusUnauthorized, "unauthenticated", "invalid caller identity")
+	}
+
+	scope := database.Scope{UserID: userID}
+
+	if apiKey, ok := ctx.Locals("api_key").(models.APIKey); ok {
+		scope.OrgID = apiKey.OrgID
+		scope.TemplateIDs = apiKey.TemplateIDs
+		return scope, nil
+	}
+
+	if orgIDStr := ctx.Get("X-Org-ID"); orgIDStr != "" {
+		orgID, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			return database.Scope{}, badRequest("invalid_org_id", "Invalid X-Org-ID header")
+		}
+		scope.OrgID = &orgID
+	}
+
+	return scope, nil
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) GrantShare(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.ShareAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	share, err := h.db.GrantShare(scope, templateID, data)
+	if err != nil {
+		return fmt.Errorf("sharing template %s: %w", templateID, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"share": share.ToDTO(),
+		},
+	})
+}
+
+func (h *HTTPHandler) GetShares(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	shares, err := h.db.GetShares(scope, templateID)
+	if err != nil {
+		return fmt.Errorf("listing shares of template %s: %w", templateID, err)
+	}
+
+	dto := make([]models.ShareDTO, 0, len(shares))
+	for _, s := range shares {
+		dto = append(dto, s.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"shares": dto,
+		},
+	})
+}
+
+func (h *HTTPHandler) RevokeShare(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.db.RevokeShare(scope, templateID, ctx.Params("userId")); err != nil {
+		return fmt.Errorf("revoking share on template %s: %w", templateID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/auth"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultShareLinkTTL = 7 * 24 * time.Hour
+	maxShareLinkTTL     = 90 * 24 * time.Hour
+)
+
+func (h *HTTPHandler) CreateShareLink(ctx *fiber.Ctx) error {
+	if len(h.opts.ShareLinkSecret) == 0 {
+		return newRequestError(fiber.StatusServiceUnavailable, "share_links_disabled", "Share links are not enabled")
+	}
+
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.CreateShareLinkAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	actions := data.Actions
+	if len(actions) == 0 {
+		actions = []string{auth.ShareActionView, auth.ShareActionRender}
+	}
+	for _, a := range actions {
+		if a != auth.ShareActionView && a != auth.ShareActionRender {
+			return badRequest("invalid_action", "Invalid share link action: %s", a)
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(defaultShareLinkTTL)
+	if data.ExpiresAt != nil {
+		expiresAt = *data.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.Sub(now) > maxShareLinkTTL {
+		return badRequest("invalid_expiry", "expires_at must be in the future and within 90 days")
+	}
+
+	id, version, err := h.db.ShareLinkVersion(scope, templateID, data.Version)
+	if err != nil {
+		return fmt.Errorf("creating share link for template %s: %w", templateID, err)
+	}
+
+	link := auth.ShareLink{
+		TemplateID: id,
+		Version:    version,
+		Actions:    actions,
+		ExpiresAt:  expiresAt,
+	}
+
+	token, err := auth.SignShareLink(h.opts.ShareLinkSecret, link)
+	if err != nil {
+		return fmt.Errorf("signing share link: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"share_link": models.ShareLinkDTO{
+				Token:      token,
+				TemplateID: id.String(),
+				Version:    version,
+				Actions:    actions,
+				ExpiresAt:  expiresAt.UTC().Truncate(time.Second),
+			},
+		},
+	})
+}
+
+func (h *HTTPHandler) GetSharedTemplate(ctx *fiber.Ctx) error {
+	link, err := h.shareLink(ctx, auth.ShareActionView)
+	if err != nil {
+		return err
+	}
+
+	template, err := h.db.GetSharedTemplate(link.TemplateID, link.Version)
+	if err != nil {
+		return fmt.Errorf("retrieving shared template: %w", err)
+	}
+
+	dto := template.ToDTO()
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"template": models.SharedTemplateDTO{
+				Name:        dto.Name,
+				Description: dto.Description,
+				Type:        dto.Type,
+				Version:     link.Version,
+				Fields:      dto.Fields,
+			},
+		},
+	})
+}
+
+func (h *HTTPHandler) RenderSharedTemplate(ctx *fiber.Ctx) error {
+	link, err := h.shareLink(ctx, auth.ShareActionRender)
+	if err != nil {
+		return err
+	}
+
+	var req models.RenderAPI
+	if err := ctx.BodyParser(&req); err != nil {
+		return errInvalidBody
+	}
+
+	template, err := h.db.GetSharedTemplate(link.TemplateID, link.Version)
+	if err != nil {
+		return fmt.Errorf("retrieving shared template: %w", err)
+	}
+
+	result, err := h.renderVersion(ctx, template, template.Versions[0], req.Values, req.Locale)
+	if err != nil {
+		return fmt.Errorf("rendering shared template: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"preview_html": result,
+		},
+	})
+}
+
+// shareLink verifies the token route parameter and checks that it grants action.
+func (h *HTTPHandler) shareLink(ctx *fiber.Ctx, action string) (auth.ShareLink, error) {
+	link, err := auth.VerifyShareLink(h.opts.ShareLinkSecret, ctx.Params("token"), time.Now())
+	if err != nil {
+		log.Warn().Err(err).Msg("rejected share link")
+		return link, newRequestError(fiber.StatusUnauthorized, "invalid_share_link", "Invalid or expired share link")
+	}
+
+	if !link.Allows(action) {
+		return link, newRequestError(fiber.StatusForbidden, "action_not_allowed", "Share link does not allow this action")
+	}
+
+	ctx.Locals("share_link", link)
+	return link, nil
+}
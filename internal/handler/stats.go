@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) GetTemplateStats(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter, err := statsFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.db.GetTemplateStats(scope, templateID, filter)
+	if err != nil {
+		return fmt.Errorf("computing stats of template %s: %w", templateID, err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"stats": stats,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetUsageStats(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	filter, err := statsFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	stats, err := h.db.GetUsageStats(scope, filter)
+	if err != nil {
+		return fmt.Errorf("computing usage stats: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"stats": stats,
+		},
+	})
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) CreateTag(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.TagAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	tag, err := h.db.CreateTag(scope, data)
+	if err != nil {
+		return fmt.Errorf("creating tag: %w", err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"tag": tag.ToDTO(),
+		},
+	})
+}
+
+func (h *HTTPHandler) GetTags(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	tags, err := h.db.GetTags(scope)
+	if err != nil {
+		return fmt.Errorf("retrieving tags: %w", err)
+	}
+
+	dto := make([]models.TagDTO, 0, len(tags))
+	for _, t := range tags {
+		dto = append(dto, t.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"tags": dto,
+		},
+	})
+}
+
+func (h *HTTPHandler) DeleteTag(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	tagID := ctx.Params("id")
+	if err := h.db.DeleteTag(scope, tagID); err != nil {
+		return fmt.Errorf("deleting tag %s: %w", tagID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *HTTPHandler) SetTemplateTags(ctx *fiber.Ctx) error {
+	templateID := ctx.Params("id")
+	if templateID == "" {
+		return errTemplateIDMissing
+	}
+
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.TemplateTagsAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	tags, err := h.db.SetTemplateTags(scope, templateID, data.Tags)
+	if err != nil {
+		return fmt.Errorf("tagging template %s: %w", templateID, err)
+	}
+
+	dto := make([]models.TagDTO, 0, len(tags))
+	for _, t := range tags {
+		dto = append(dto, t.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"tags": dto,
+		},
+	})
+}
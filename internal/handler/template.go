@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/internal/jsonschema"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) ReplaceTemplate(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body models.CreateTemplateAPI
+	if err := ctx.BodyParser(&body); err != nil {
+		return errInvalidBody
+	}
+
+	templateID := ctx.Params("id")
+	etag, err := h.db.UpdateTemplate(scope, templateID, body, ctx.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fmt.Errorf("replacing template %s: %w", templateID, err)
+	}
+
+	ctx.Set(fiber.HeaderETag, etag)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HTTPHandler) PatchTemplate(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	contentType := strings.ToLower(ctx.Get(fiber.HeaderContentType))
+	if !strings.HasPrefix(contentType, "application/merge-patch+json") &&
+		!strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		ctx.Set("Accept-Patch", "application/merge-patch+json")
+		return newRequestError(fiber.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Expected an application/merge-patch+json body")
+	}
+
+	templateID := ctx.Params("id")
+	etag, err := h.db.PatchTemplate(scope, templateID, ctx.Body(), ctx.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		return fmt.Errorf("patching template %s: %w", templateID, err)
+	}
+
+	ctx.Set(fiber.HeaderETag, etag)
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HTTPHandler) RemoveTemplate(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	templateID := ctx.Params("id")
+	if err := h.db.DeleteTemplate(scope, templateID, ctx.Get(fiber.HeaderIfMatch)); err != nil {
+		return fmt.Errorf("deleting template %s: %w", templateID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HTTPHandler) GetTemplateSchema(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	templateID := ctx.Params("id")
+	template, err := h.db.GetTemplateByID(scope, templateID)
+	if err != nil {
+		return fmt.Errorf("retrieving template %s: %w", templateID, err)
+	}
+
+	etag := database.TemplateETag(template)
+	ctx.Set(fiber.HeaderETag, etag)
+	if ifNoneMatch := ctx.Get(fiber.HeaderIfNoneMatch); ifNoneMatch != "" && database.MatchETag(ifNoneMatch, etag, true) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	schema, err := jsonschema.Template(template.ToDTO(), ctx.BaseURL()+ctx.Path())
+	if err != nil {
+		return fmt.Errorf("describing fields of template %s: %w", templateID, err)
+	}
+
+	return ctx.JSON(schema, "application/schema+json")
+}
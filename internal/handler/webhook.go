@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (h *HTTPHandler) CreateWebhook(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	var data models.WebhookAPI
+	if err := ctx.BodyParser(&data); err != nil {
+		return errInvalidBody
+	}
+
+	webhook, err := h.db.CreateWebhook(scope, data)
+	if err != nil {
+		return fmt.Errorf("creating webhook: %w", err)
+	}
+
+	// The signing secret is only ever returned here.
+	return ctx.Status(fiber.StatusCreated).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"webhook": webhook.ToDTO(),
+			"secret":  webhook.Secret,
+		},
+	})
+}
+
+func (h *HTTPHandler) GetWebhooks(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := h.db.GetWebhooks(scope)
+	if err != nil {
+		return fmt.Errorf("retrieving webhooks: %w", err)
+	}
+
+	dto := make([]models.WebhookDTO, 0, len(webhooks))
+	for _, w := range webhooks {
+		dto = append(dto, w.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"webhooks": dto,
+		},
+	})
+}
+
+func (h *HTTPHandler) DeleteWebhook(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	webhookID := ctx.Params("id")
+	if err := h.db.DeleteWebhook(scope, webhookID); err != nil {
+		return fmt.Errorf("deleting webhook %s: %w", webhookID, err)
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (h *HTTPHandler) GetWebhookDeliveries(ctx *fiber.Ctx) error {
+	scope, err := requestScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	webhookID := ctx.Params("id")
+	deliveries, err := h.db.GetWebhookDeliveries(scope, webhookID, ctx.Query("status"), ctx.QueryInt("limit", 0))
+	if err != nil {
+		return fmt.Errorf("retrieving deliveries of webhook %s: %w", webhookID, err)
+	}
+
+	dto := make([]models.WebhookDeliveryDTO, 0, len(deliveries))
+	for _, d := range deliveries {
+		dto = append(dto, d.ToDTO())
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(models.Response{
+		Error: false,
+		Data: fiber.Map{
+			"deliveries": dto,
+		},
+	})
+}
@@ -0,0 +1,416 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// node is a single element of a parsed message pattern.
+type node interface{}
+
+// textNode is literal text copied to the output verbatim.
+type textNode string
+
+// hashNode is the '#' placeholder inside a plural branch.
+type hashNode struct{}
+
+// argNode is a simple placeholder such as {name} or {amount, number}.
+type argNode struct {
+	name  string
+	kind  string
+	style string
+}
+
+// pluralNode is a {count, plural, ...} or {pos, selectordinal, ...} argument.
+type pluralNode struct {
+	name    string
+	ordinal bool
+	offset  float64
+	cases   map[string][]node
+}
+
+// selectNode is a {gender, select, ...} argument.
+type selectNode struct {
+	name  string
+	cases map[string][]node
+}
+
+// Format renders an ICU-style message pattern for the given locale.
+// Supported syntax: {arg}, {arg, number}, {arg, plural, ...} with offset and
+// =N selectors, {arg, selectordinal, ...}, {arg, select, ...} and apostrophe quoting.
+//
+// Parameters:
+//   - pattern: The message pattern.
+//   - locale: The locale used to pick plural categories.
+//   - args: The values substituted into placeholders.
+//
+// Returns:
+//   - string: The formatted message.
+//   - error: An error if the pattern is malformed or a plural argument is not numeric.
+func Format(pattern, locale string, args map[string]any) (string, error) {
+	nodes, err := parse(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := eval(&b, nodes, locale, args, nil); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// Validate checks that pattern is a well-formed message.
+//
+// Parameters:
+//   - pattern: The message pattern.
+//
+// Returns:
+//   - error: An error describing the first syntax problem, if any.
+func Validate(pattern string) error {
+	_, err := parse(pattern)
+	return err
+}
+
+func eval(b *strings.Builder, nodes []node, locale string, args map[string]any, hash *float64) error {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case textNode:
+			b.WriteString(string(n))
+		case hashNode:
+			if hash != nil {
+				b.WriteString(formatNumber(*hash, ""))
+			} else {
+				b.WriteByte('#')
+			}
+		case argNode:
+			v, ok := args[n.name]
+			if !ok {
+				b.WriteString("{" + n.name + "}")
+				continue
+			}
+			if n.kind == "number" {
+				f, ok := toFloat(v)
+				if !ok {
+					return fmt.Errorf("argument %q is not a number", n.name)
+				}
+				b.WriteString(formatNumber(f, n.style))
+				continue
+			}
+			b.WriteString(fmt.Sprint(v))
+		case selectNode:
+			branch, ok := n.cases[fmt.Sprint(args[n.name])]
+			if !ok {
+				branch = n.cases["other"]
+			}
+			if err := eval(b, branch, locale, args, hash); err != nil {
+				return err
+			}
+		case pluralNode:
+			f, ok := toFloat(args[n.name])
+			if !ok {
+				return fmt.Errorf("argument %q is not a number", n.name)
+			}
+
+			branch, ok := n.cases["="+formatNumber(f, "")]
+			if !ok {
+				var category string
+				if n.ordinal {
+					category = OrdinalCategory(locale, f-n.offset)
+				} else {
+					category = PluralCategory(locale, f-n.offset)
+				}
+				if branch, ok = n.cases[category]; !ok {
+					branch = n.cases["other"]
+				}
+			}
+
+			value := f - n.offset
+			if err := eval(b, branch, locale, args, &value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func formatNumber(f float64, style string) string {
+	switch style {
+	case "integer":
+		return strconv.FormatFloat(f, 'f', 0, 64)
+	case "percent":
+		return strconv.FormatFloat(f*100, 'f', -1, 64) + "%"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// parser turns a message pattern into a tree of nodes.
+type parser struct {
+	src []rune
+	pos int
+}
+
+func parse(pattern string) ([]node, error) {
+	p := &parser{src: []rune(pattern)}
+	return p.message(false, false)
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("message syntax error at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *parser) expect(r rune) error {
+	p.skipSpace()
+	if p.peek() != r {
+		return p.errorf("expected %q", r)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		if unicode.IsSpace(r) || r == ',' || r == '{' || r == '}' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// message parses literal text and arguments until the end of input or,
+// when nested, until the closing brace of the enclosing branch.
+func (p *parser) message(inPlural, nested bool) ([]node, error) {
+	var (
+		nodes []node
+		text  strings.Builder
+	)
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '\'':
+			p.pos++
+			next := p.peek()
+			if next == '\'' {
+				text.WriteRune('\'')
+				p.pos++
+				continue
+			}
+			if next != '{' && next != '}' && !(inPlural && next == '#') {
+				text.WriteRune('\'')
+				continue
+			}
+			for p.pos < len(p.src) {
+				if p.src[p.pos] == '\'' {
+					if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'' {
+						text.WriteRune('\'')
+						p.pos += 2
+						continue
+					}
+					p.pos++
+					break
+				}
+				text.WriteRune(p.src[p.pos])
+				p.pos++
+			}
+		case c == '{':
+			flush()
+			p.pos++
+			n, err := p.argument()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		case c == '}':
+			if !nested {
+				return nil, p.errorf("unexpected '}'")
+			}
+			flush()
+			return nodes, nil
+		case c == '#' && inPlural:
+			flush()
+			nodes = append(nodes, hashNode{})
+			p.pos++
+		default:
+			text.WriteRune(c)
+			p.pos++
+		}
+	}
+
+	if nested {
+		return nil, p.errorf("unterminated message")
+	}
+
+	flush()
+	return nodes, nil
+}
+
+// argument parses everything after an opening '{' up to and including the matching '}'.
+func (p *parser) argument() (node, error) {
+	p.skipSpace()
+	name := p.ident()
+	if name == "" {
+		return nil, p.errorf("missing argument name")
+	}
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		return argNode{name: name}, nil
+	}
+	if err := p.expect(','); err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	kind := p.ident()
+	p.skipSpace()
+
+	switch kind {
+	case "plural", "selectordinal":
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		offset, cases, err := p.cases(true)
+		if err != nil {
+			return nil, err
+		}
+		return pluralNode{name: name, ordinal: kind == "selectordinal", offset: offset, cases: cases}, nil
+	case "select":
+		if err := p.expect(','); err != nil {
+			return nil, err
+		}
+		_, cases, err := p.cases(false)
+		if err != nil {
+			return nil, err
+		}
+		return selectNode{name: name, cases: cases}, nil
+	case "":
+		return nil, p.errorf("missing argument type")
+	}
+
+	var style string
+	if p.peek() == ',' {
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] != '}' {
+			p.pos++
+		}
+		style = strings.TrimSpace(string(p.src[start:p.pos]))
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+
+	return argNode{name: name, kind: kind, style: style}, nil
+}
+
+// cases parses the selector/branch pairs of a plural or select argument,
+// consuming the closing '}' of the argument.
+func (p *parser) cases(plural bool) (float64, map[string][]node, error) {
+	var offset float64
+	cases := make(map[string][]node)
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return 0, nil, p.errorf("unterminated argument")
+		}
+		if p.peek() == '}' {
+			p.pos++
+			break
+		}
+
+		selector := p.ident()
+		if selector == "" {
+			return 0, nil, p.errorf("expected selector")
+		}
+
+		if plural && strings.HasPrefix(selector, "offset:") {
+			v, err := strconv.ParseFloat(strings.TrimPrefix(selector, "offset:"), 64)
+			if err != nil {
+				return 0, nil, p.errorf("invalid offset %q", selector)
+			}
+			offset = v
+			continue
+		}
+
+		if err := p.expect('{'); err != nil {
+			return 0, nil, err
+		}
+		branch, err := p.message(plural, true)
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := p.expect('}'); err != nil {
+			return 0, nil, err
+		}
+
+		cases[selector] = branch
+	}
+
+	if _, ok := cases["other"]; !ok {
+		return 0, nil, p.errorf("missing 'other' branch")
+	}
+
+	return offset, cases, nil
+}
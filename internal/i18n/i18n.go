@@ -0,0 +1,148 @@
+// Package i18n provides message catalogs for templates. Catalogs map message keys
+// to translations per locale, and messages use a subset of ICU MessageFormat for
+// placeholders, plurals and selects.
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrMissingMessage is returned when a key has no translation in any candidate locale.
+var ErrMissingMessage = errors.New("missing message")
+
+// Messages maps a message key to its translations, keyed by locale.
+type Messages map[string]map[string]string
+
+// Translator resolves message keys for a single requested locale.
+type Translator struct {
+	messages map[string]map[string]string // key -> normalized locale -> pattern
+	locales  []string                     // candidate locales in lookup order
+}
+
+// NewTranslator creates a Translator that looks up messages in locale first,
+// then in its base language, then in the fallback locale.
+//
+// Parameters:
+//   - messages: The catalog messages.
+//   - locale: The requested locale.
+//   - fallback: The catalog default locale.
+//
+// Returns:
+//   - *Translator: The translator instance.
+func NewTranslator(messages Messages, locale, fallback string) *Translator {
+	normalized := make(map[string]map[string]string, len(messages))
+	for key, translations := range messages {
+		byLocale := make(map[string]string, len(translations))
+		for l, pattern := range translations {
+			byLocale[normalizeLocale(l)] = pattern
+		}
+		normalized[key] = byLocale
+	}
+
+	var locales []string
+	seen := make(map[string]bool)
+	for _, l := range []string{locale, baseLanguage(locale), fallback, baseLanguage(fallback)} {
+		l = normalizeLocale(l)
+		if l != "" && !seen[l] {
+			seen[l] = true
+			locales = append(locales, l)
+		}
+	}
+
+	return &Translator{messages: normalized, locales: locales}
+}
+
+// Translate formats the message stored under key with args.
+//
+// Parameters:
+//   - key: The message key, e.g. "invoice.total".
+//   - args: The values substituted into placeholders.
+//
+// Returns:
+//   - string: The formatted message.
+//   - error: ErrMissingMessage if no translation exists, or a formatting error.
+func (t *Translator) Translate(key string, args map[string]any) (string, error) {
+	translations, ok := t.messages[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrMissingMessage, key)
+	}
+
+	for _, l := range t.locales {
+		if pattern, ok := translations[l]; ok {
+			return Format(pattern, l, args)
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrMissingMessage, key)
+}
+
+// ValidateMessages checks every pattern in messages for syntax errors.
+//
+// Parameters:
+//   - messages: The catalog messages.
+//
+// Returns:
+//   - error: An error naming the first invalid key and locale, if any.
+func ValidateMessages(messages Messages) error {
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			return errors.New("message key must not be empty")
+		}
+		for l, pattern := range messages[key] {
+			if err := Validate(pattern); err != nil {
+				return fmt.Errorf("message %q (%s): %w", key, l, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// PreferredLocale returns the highest-weighted language tag of an Accept-Language header.
+//
+// Parameters:
+//   - header: The Accept-Language header value.
+//
+// Returns:
+//   - string: The preferred locale, or an empty string if none is usable.
+func PreferredLocale(header string) string {
+	best, bestQ := "", -1.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(normalizeLocale(locale), "-")
+	return base
+}
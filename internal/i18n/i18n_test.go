@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFormat verifies placeholder, plural and select formatting.
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		locale  string
+		args    map[string]any
+		want    string
+	}{
+		{name: "placeholder", pattern: "Total: {amount}", locale: "en", args: map[string]any{"amount": "42 EUR"}, want: "Total: 42 EUR"},
+		{name: "missing placeholder", pattern: "Hi {name}", locale: "en", args: nil, want: "Hi {name}"},
+		{name: "plural one", pattern: "{count, plural, one {# item} other {# items}}", locale: "en", args: map[string]any{"count": 1}, want: "1 item"},
+		{name: "plural other", pattern: "{count, plural, one {# item} other {# items}}", locale: "en", args: map[string]any{"count": 3}, want: "3 items"},
+		{name: "plural exact", pattern: "{count, plural, =0 {no items} other {# items}}", locale: "en", args: map[string]any{"count": 0}, want: "no items"},
+		{name: "plural russian few", pattern: "{n, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", locale: "ru", args: map[string]any{"n": 3}, want: "3 файла"},
+		{name: "plural offset", pattern: "{n, plural, offset:1 =0 {nobody} =1 {you} one {you and # other} other {you and # others}}", locale: "en", args: map[string]any{"n": 3}, want: "you and 2 others"},
+		{name: "plural string number", pattern: "{n, plural, one {one} other {many}}", locale: "en", args: map[string]any{"n": "1"}, want: "one"},
+		{name: "select", pattern: "{g, select, female {She} male {He} other {They}} paid", locale: "en", args: map[string]any{"g": "x"}, want: "They paid"},
+		{name: "ordinal", pattern: "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", locale: "en", args: map[string]any{"n": 22}, want: "22nd"},
+		{name: "quoted", pattern: "'{literal}' it''s", locale: "en", args: nil, want: "{literal} it's"},
+		{name: "number style", pattern: "{p, number, percent}", locale: "en", args: map[string]any{"p": 0.5}, want: "50%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.pattern, tt.locale, tt.args)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestValidate verifies that malformed patterns are rejected.
+func TestValidate(t *testing.T) {
+	for _, pattern := range []string{
+		"{unclosed",
+		"stray }",
+		"{n, plural, one {x}}",
+		"{n, plural, other {x}",
+	} {
+		require.Error(t, Validate(pattern), pattern)
+	}
+}
+
+// TestTranslator verifies locale fallback order.
+func TestTranslator(t *testing.T) {
+	messages := Messages{
+		"invoice.total": {"en": "Total", "de": "Summe"},
+		"invoice.items": {"en": "{count, plural, one {# item} other {# items}}"},
+	}
+
+	tr := NewTranslator(messages, "de_AT", "en")
+
+	got, err := tr.Translate("invoice.total", nil)
+	require.NoError(t, err)
+	require.Equal(t, "Summe", got)
+
+	got, err = tr.Translate("invoice.items", map[string]any{"count": 2})
+	require.NoError(t, err)
+	require.Equal(t, "2 items", got)
+
+	_, err = tr.Translate("unknown", nil)
+	require.ErrorIs(t, err, ErrMissingMessage)
+}
+
+// TestPreferredLocale verifies Accept-Language parsing.
+func TestPreferredLocale(t *testing.T) {
+	require.Equal(t, "de-DE", PreferredLocale("en;q=0.5, de-DE, fr;q=0.8"))
+	require.Equal(t, "", PreferredLocale(""))
+}
@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"math"
+)
+
+// PluralCategory returns the CLDR cardinal plural category ("zero", "one", "two",
+// "few", "many" or "other") of n for the given locale. Only the rules of commonly
+// used languages are implemented; unknown languages fall back to the English rule.
+//
+// Parameters:
+//   - locale: The locale tag, e.g. "en", "de-AT", "pt_BR".
+//   - n: The number being pluralized.
+//
+// Returns:
+//   - string: The plural category.
+func PluralCategory(locale string, n float64) string {
+	n = math.Abs(n)
+	isInt := n == math.Trunc(n)
+	i := int64(n)
+	mod10, mod100 := i%10, i%100
+
+	switch baseLanguage(locale) {
+	case "ja", "zh", "ko", "th", "vi", "id", "ms":
+		return "other"
+	case "fr", "hi":
+		if i == 0 || i == 1 {
+			return "one"
+		}
+		return "other"
+	case "ru", "uk", "be":
+		if !isInt {
+			return "other"
+		}
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+		return "many"
+	case "pl":
+		if !isInt {
+			return "other"
+		}
+		switch {
+		case i == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+		return "many"
+	case "cs", "sk":
+		if !isInt {
+			return "many"
+		}
+		switch {
+		case i == 1:
+			return "one"
+		case i >= 2 && i <= 4:
+			return "few"
+		}
+		return "other"
+	case "ar":
+		if !isInt {
+			return "other"
+		}
+		switch {
+		case i == 0:
+			return "zero"
+		case i == 1:
+			return "one"
+		case i == 2:
+			return "two"
+		case mod100 >= 3 && mod100 <= 10:
+			return "few"
+		case mod100 >= 11:
+			return "many"
+		}
+		return "other"
+	}
+
+	if isInt && i == 1 {
+		return "one"
+	}
+	return "other"
+}
+
+// OrdinalCategory returns the CLDR ordinal plural category of n for the given
+// locale. Ordinal rules are only defined for English; other languages use "other".
+//
+// Parameters:
+//   - locale: The locale tag.
+//   - n: The position being formatted.
+//
+// Returns:
+//   - string: The ordinal category.
+func OrdinalCategory(locale string, n float64) string {
+	if baseLanguage(locale) != "en" || n != math.Trunc(n) {
+		return "other"
+	}
+
+	i := int64(math.Abs(n))
+	mod10, mod100 := i%10, i%100
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return "one"
+	case mod10 == 2 && mod100 != 12:
+		return "two"
+	case mod10 == 3 && mod100 != 13:
+		return "few"
+	}
+	return "other"
+}
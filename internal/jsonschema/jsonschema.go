@@ -0,0 +1,334 @@
+// Package jsonschema describes the values a template's fields accept as a JSON
+// Schema (draft 2020-12), so that clients and form builders can validate and
+// render input forms.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dashboard-platform/template-service/models"
+)
+
+// Draft is the meta-schema generated schemas conform to.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// maxDepth bounds how deeply lists may nest.
+const maxDepth = 5
+
+type Schema struct {
+	Schema      string     `json:"$schema,omitempty"`
+	ID          string     `json:"$id,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Type        string     `json:"type,omitempty"`
+	Format      string     `json:"format,omitempty"`
+	Const       any        `json:"const,omitempty"`
+	Enum        []any      `json:"enum,omitempty"`
+	OneOf       []*Schema  `json:"oneOf,omitempty"`
+	Properties  Properties `json:"properties,omitempty"`
+	Required    []string   `json:"required,omitempty"`
+	Items       *Schema    `json:"items,omitempty"`
+	UniqueItems bool       `json:"uniqueItems,omitempty"`
+}
+
+// Properties holds the members of an object in field order, which form builders
+// use as the display order.
+type Properties []Property
+
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(schema)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// scalars maps field types to the schema of their values. Unknown types accept
+// strings, as text fields do.
+var scalars = map[string]Schema{
+	"text":     {Type: "string"},
+	"textarea": {Type: "string"},
+	"email":    {Type: "string", Format: "email"},
+	"url":      {Type: "string", Format: "uri"},
+	"date":     {Type: "string", Format: "date"},
+	"datetime": {Type: "string", Format: "date-time"},
+	"time":     {Type: "string", Format: "time"},
+	"number":   {Type: "number"},
+	"integer":  {Type: "integer"},
+	"boolean":  {Type: "boolean"},
+	"checkbox": {Type: "boolean"},
+}
+
+// options is the structure of a field's options. A bare array is shorthand for
+// {"choices": [...]}.
+type options struct {
+	Choices []choice          `json:"choices"` // allowed values of select and multiselect fields
+	Items   []models.FieldDTO `json:"items"`   // fields of each entry of a list field
+}
+
+// choice is an allowed value, given either as a scalar or as {"value", "label"}.
+type choice struct {
+	Value any    `json:"value"`
+	Label string `json:"label"`
+}
+
+func (c *choice) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		type plain choice
+		if err := json.Unmarshal(data, (*plain)(c)); err != nil {
+			return err
+		}
+	} else if err := json.Unmarshal(data, &c.Value); err != nil {
+		return err
+	}
+
+	switch c.Value.(type) {
+	case string, float64, bool:
+		return nil
+	}
+	return fmt.Errorf("invalid choice %s", data)
+}
+
+func parseOptions(raw json.RawMessage) (options, error) {
+	var opts options
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return opts, nil
+	}
+
+	var err error
+	if raw[0] == '[' {
+		err = json.Unmarshal(raw, &opts.Choices)
+	} else {
+		err = json.Unmarshal(raw, &opts)
+	}
+	if err != nil {
+		return opts, fmt.Errorf("invalid options: %w", err)
+	}
+
+	return opts, nil
+}
+
+// Template returns the schema of the values accepted by a template.
+//
+// Parameters:
+//   - template: The template with its fields.
+//   - id: The URI the schema is published at; omitted if empty.
+//
+// Returns:
+//   - *Schema: The root schema of the values object.
+//   - error: An error if the options of a field are malformed.
+func Template(template models.TemplateDTO, id string) (*Schema, error) {
+	schema, err := object(template.Fields, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.Schema = Draft
+	schema.ID = id
+	schema.Title = template.Name
+	schema.Description = template.Description
+	return schema, nil
+}
+
+// Field returns the schema of a field's value. It is used to check the options
+// of fields before they are stored.
+//
+// Parameters:
+//   - field: The field definition.
+//
+// Returns:
+//   - *Schema: The schema of the value.
+//   - error: An error if the field's options are malformed.
+func Field(field models.FieldDTO) (*Schema, error) {
+	return fieldSchema(field, 0)
+}
+
+// object describes a values object with one member per field.
+func object(fields []models.FieldDTO, depth int) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: Properties{}}
+
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if strings.TrimSpace(f.Key) == "" {
+			return nil, errors.New("field key is missing")
+		}
+		if seen[f.Key] {
+			return nil, fmt.Errorf("duplicate field key %q", f.Key)
+		}
+		seen[f.Key] = true
+
+		s, err := fieldSchema(f, depth)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.Key, err)
+		}
+
+		schema.Properties = append(schema.Properties, Property{Name: f.Key, Schema: s})
+		if f.Required {
+			schema.Required = append(schema.Required, f.Key)
+		}
+	}
+
+	return schema, nil
+}
+
+func fieldSchema(f models.FieldDTO, depth int) (*Schema, error) {
+	opts, err := parseOptions(f.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *Schema
+	switch f.Type {
+	case "multiselect":
+		schema = &Schema{Type: "array", Items: scalar("text", opts.Choices), UniqueItems: true}
+	case "list":
+		if depth+1 >= maxDepth {
+			return nil, fmt.Errorf("lists nest deeper than %d levels", maxDepth)
+		}
+
+		items := scalar("text", opts.Choices)
+		if len(opts.Items) > 0 {
+			if items, err = object(opts.Items, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		schema = &Schema{Type: "array", Items: items}
+	case "select":
+		schema = scalar("text", opts.Choices)
+	default:
+		schema = scalar(f.Type, opts.Choices)
+	}
+
+	schema.Title = f.Label
+	return schema, nil
+}
+
+// scalar returns the schema of a value of the given type, restricted to choices
+// if there are any. Labelled choices become a oneOf of constants, which form
+// builders show as the option labels.
+func scalar(fieldType string, choices []choice) *Schema {
+	s, ok := scalars[fieldType]
+	if !ok {
+		s = scalars["text"]
+	}
+	if len(choices) == 0 {
+		return &s
+	}
+
+	labelled := false
+	for _, c := range choices {
+		labelled = labelled || c.Label != ""
+	}
+
+	for _, c := range choices {
+		if labelled {
+			title := c.Label
+			if title == "" {
+				title = fmt.Sprint(c.Value)
+			}
+			s.OneOf = append(s.OneOf, &Schema{Const: c.Value, Title: title})
+		} else {
+			s.Enum = append(s.Enum, c.Value)
+		}
+	}
+
+	// The choices decide the type; a select may offer numbers.
+	s.Type, s.Format = choiceType(choices), ""
+	return &s
+}
+
+// choiceType returns the JSON type shared by all choices, or "" if they differ.
+func choiceType(choices []choice) string {
+	var types []string
+	for _, c := range choices {
+		t := "string"
+		switch c.Value.(type) {
+		case float64:
+			t = "number"
+		case bool:
+			t = "boolean"
+		}
+		if len(types) > 0 && types[0] != t {
+			return ""
+		}
+		types = append(types, t)
+	}
+	return types[0]
+}
+
+// formatExamples holds example values of formatted strings.
+var formatExamples = map[string]string{
+	"email":     "user@example.com",
+	"uri":       "https://example.com",
+	"date":      "2000-01-01",
+	"date-time": "2000-01-01T00:00:00Z",
+	"time":      "00:00:00",
+}
+
+// Example returns a value conforming to the schema, for use as sample data. Choices
+// yield their first value, arrays a single item and strings their title.
+//
+// Parameters:
+//   - s: A schema returned by Template or Field.
+//
+// Returns:
+//   - any: The example value.
+func Example(s *Schema) any {
+	switch {
+	case s.Const != nil:
+		return s.Const
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.OneOf) > 0:
+		return Example(s.OneOf[0])
+	}
+
+	switch s.Type {
+	case "object":
+		values := make(map[string]any, len(s.Properties))
+		for _, p := range s.Properties {
+			values[p.Name] = Example(p.Schema)
+		}
+		return values
+	case "array":
+		return []any{Example(s.Items)}
+	case "number", "integer":
+		return 0
+	case "boolean":
+		return false
+	}
+
+	if example, ok := formatExamples[s.Format]; ok {
+		return example
+	}
+	if s.Title != "" {
+		return s.Title
+	}
+	return "text"
+}
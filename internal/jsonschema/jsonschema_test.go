@@ -0,0 +1,154 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    models.FieldDTO
+		expected string
+	}{
+		{
+			name:     "text",
+			field:    models.FieldDTO{Key: "name", Label: "Name", Type: "text"},
+			expected: `{"title":"Name","type":"string"}`,
+		},
+		{
+			name:     "unknown type",
+			field:    models.FieldDTO{Key: "logo", Type: "image"},
+			expected: `{"type":"string"}`,
+		},
+		{
+			name:     "date",
+			field:    models.FieldDTO{Key: "due", Type: "date"},
+			expected: `{"type":"string","format":"date"}`,
+		},
+		{
+			name:     "select",
+			field:    models.FieldDTO{Key: "size", Type: "select", Options: json.RawMessage(`["s","m","l"]`)},
+			expected: `{"type":"string","enum":["s","m","l"]}`,
+		},
+		{
+			name:     "labelled choices",
+			field:    models.FieldDTO{Key: "size", Type: "select", Options: json.RawMessage(`{"choices":[{"value":1,"label":"One"},{"value":2}]}`)},
+			expected: `{"type":"number","oneOf":[{"title":"One","const":1},{"title":"2","const":2}]}`,
+		},
+		{
+			name:     "mixed choices",
+			field:    models.FieldDTO{Key: "v", Type: "select", Options: json.RawMessage(`["a",1]`)},
+			expected: `{"enum":["a",1]}`,
+		},
+		{
+			name:     "multiselect",
+			field:    models.FieldDTO{Key: "tags", Type: "multiselect", Options: json.RawMessage(`["a","b"]`)},
+			expected: `{"type":"array","items":{"type":"string","enum":["a","b"]},"uniqueItems":true}`,
+		},
+		{
+			name:     "list of text",
+			field:    models.FieldDTO{Key: "notes", Type: "list"},
+			expected: `{"type":"array","items":{"type":"string"}}`,
+		},
+		{
+			name: "nested list",
+			field: models.FieldDTO{Key: "lines", Type: "list", Options: json.RawMessage(`{"items":[
+				{"key":"sku","label":"SKU","required":true},
+				{"key":"qty","type":"integer"},
+				{"key":"notes","type":"list"}]}`)},
+			expected: `{"type":"array","items":{"type":"object","properties":{` +
+				`"sku":{"title":"SKU","type":"string"},` +
+				`"qty":{"type":"integer"},` +
+				`"notes":{"type":"array","items":{"type":"string"}}},"required":["sku"]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := Field(tt.field)
+			require.NoError(t, err)
+
+			data, err := json.Marshal(schema)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestFieldInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		err     string
+	}{
+		{"not an object", `"small"`, "invalid options"},
+		{"object choice", `[{"a":1}]`, "invalid choice"},
+		{"nested choice", `[["a"]]`, "invalid choice"},
+		{"item without key", `{"items":[{"label":"x"}]}`, "field key is missing"},
+		{"duplicate item", `{"items":[{"key":"a"},{"key":"a"}]}`, "duplicate field key"},
+		{"too deep", nested(maxDepth), "nest deeper"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Field(models.FieldDTO{Key: "f", Type: "list", Options: json.RawMessage(tt.options)})
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := Field(models.FieldDTO{Key: "f", Type: "list", Options: json.RawMessage(nested(maxDepth - 2))})
+	require.NoError(t, err)
+}
+
+// nested returns the options of a list holding depth more levels of lists.
+func nested(depth int) string {
+	return strings.Repeat(`{"items":[{"key":"f","type":"list","options":`, depth) + "null" +
+		strings.Repeat("}]}", depth)
+}
+
+func TestTemplate(t *testing.T) {
+	schema, err := Template(models.TemplateDTO{
+		Name:        "Invoice",
+		Description: "Monthly invoice",
+		Fields: []models.FieldDTO{
+			{Key: "to", Label: "Recipient", Type: "email", Required: true},
+			{Key: "amount", Type: "number", Required: true},
+			{Key: "memo", Type: "textarea"},
+		},
+	}, "https://templates.example/templates/1/schema")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	// Properties keep the field order.
+	require.Equal(t, `{"$schema":"https://json-schema.org/draft/2020-12/schema",`+
+		`"$id":"https://templates.example/templates/1/schema","title":"Invoice","description":"Monthly invoice",`+
+		`"type":"object","properties":{"to":{"title":"Recipient","type":"string","format":"email"},`+
+		`"amount":{"type":"number"},"memo":{"type":"string"}},"required":["to","amount"]}`, string(data))
+}
+
+func TestExample(t *testing.T) {
+	schema, err := Template(models.TemplateDTO{
+		Fields: []models.FieldDTO{
+			{Key: "to", Type: "email"},
+			{Key: "name", Label: "Full name"},
+			{Key: "amount", Type: "number"},
+			{Key: "size", Type: "select", Options: json.RawMessage(`[{"value":"m","label":"Medium"},{"value":"l"}]`)},
+			{Key: "lines", Type: "list", Options: json.RawMessage(`{"items":[{"key":"paid","type":"boolean"},{"key":"sku"}]}`)},
+		},
+	}, "")
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]any{
+		"to":     "user@example.com",
+		"name":   "Full name",
+		"amount": 0,
+		"size":   "m",
+		"lines":  []any{map[string]any{"paid": false, "sku": "text"}},
+	}, Example(schema))
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+// APIKeyVerifier resolves plaintext API keys.
+type APIKeyVerifier interface {
+	VerifyAPIKey(key string) (models.APIKey, error)
+}
+
+// APIKeyConfig configures the APIKeyAuth middleware.
+type APIKeyConfig struct {
+	// Verifier looks up the keys sent by clients.
+	Verifier APIKeyVerifier
+
+	// Logger records rejected keys.
+	Logger zerolog.Logger
+}
+
+// APIKeyAuth verifies keys sent as "Authorization: ApiKey <key>" and stores the
+// key in c.Locals("api_key"). Requests without such a header pass through untouched.
+// A verified key does not identify the caller by itself: routes opt in to API keys
+// with AllowAPIKey, so keys are refused everywhere else. Unknown, revoked and
+// expired keys are answered with 401; failures to verify a key are passed on to
+// the error handler.
+//
+// Parameters:
+//   - cfg: The middleware configuration.
+//
+// Returns:
+//   - fiber.Handler: The middleware handler function.
+func APIKeyAuth(cfg APIKeyConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scheme, key, ok := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+		if !ok || !strings.EqualFold(scheme, "ApiKey") {
+			return c.Next()
+		}
+
+		apiKey, err := cfg.Verifier.VerifyAPIKey(strings.TrimSpace(key))
+		if errors.Is(err, database.ErrInvalidAPIKey) {
+			cfg.Logger.Warn().Err(err).Str("path", c.Path()).Msg("rejected API key")
+			c.Set(fiber.HeaderWWWAuthenticate, `ApiKey realm="template-service"`)
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid API key")
+		}
+		if err != nil {
+			return err
+		}
+
+		c.Locals("api_key", apiKey)
+		return c.Next()
+	}
+}
+
+// HasAPIKey reports whether the request was authenticated with an API key.
+func HasAPIKey(c *fiber.Ctx) bool {
+	_, ok := c.Locals("api_key").(models.APIKey)
+	return ok
+}
+
+// AllowAPIKey lets API keys holding the given scope call a route, acting as the
+// user that issued the key. Requests authenticated otherwise are not affected.
+//
+// Parameters:
+//   - scope: The API key scope the route requires.
+//
+// Returns:
+//   - fiber.Handler: The middleware handler function.
+func AllowAPIKey(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		apiKey, ok := c.Locals("api_key").(models.APIKey)
+		if !ok {
+			return c.Next()
+		}
+
+		if !apiKey.HasScope(scope) {
+			return fiber.NewError(fiber.StatusForbidden, "API key lacks the "+scope+" scope")
+		}
+
+		c.Locals("user_id", apiKey.UserID.String())
+		return c.Next()
+	}
+}
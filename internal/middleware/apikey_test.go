@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// verifierFunc adapts a function to APIKeyVerifier.
+type verifierFunc func(key string) (models.APIKey, error)
+
+func (f verifierFunc) VerifyAPIKey(key string) (models.APIKey, error) {
+	return f(key)
+}
+
+// TestAPIKeyAuth verifies that only invalid keys are answered with 401.
+func TestAPIKeyAuth(t *testing.T) {
+	verifier := verifierFunc(func(key string) (models.APIKey, error) {
+		switch key {
+		case "valid":
+			return models.APIKey{}, nil
+		case "down":
+			return models.APIKey{}, errors.New("connection refused")
+		default:
+			return models.APIKey{}, database.ErrInvalidAPIKey
+		}
+	})
+
+	app := fiber.New()
+	app.Get("/", APIKeyAuth(APIKeyConfig{Verifier: verifier, Logger: zerolog.Nop()}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"no header", "", fiber.StatusNoContent},
+		{"other scheme", "Bearer token", fiber.StatusNoContent},
+		{"valid key", "ApiKey valid", fiber.StatusNoContent},
+		{"invalid key", "ApiKey revoked", fiber.StatusUnauthorized},
+		{"verification failure", "ApiKey down", fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode)
+			require.Equal(t, tt.status == fiber.StatusUnauthorized, resp.Header.Get(fiber.HeaderWWWAuthenticate) != "")
+		})
+	}
+}
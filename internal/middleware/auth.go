@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/dashboard-platform/template-service/internal/auth"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// AuthConfig configures the Authenticate middleware.
+type AuthConfig struct {
+	// Verifier validates bearer tokens. It may be nil in trusted gateway mode.
+	Verifier *auth.Verifier
+
+	// TrustedGateway accepts the X-User-ID header as the caller identity when no
+	// bearer token is sent. Only enable it behind a gateway that sets the header.
+	TrustedGateway bool
+
+	// Next skips the middleware for requests it returns true for.
+	Next func(c *fiber.Ctx) bool
+
+	// Logger records rejected requests.
+	Logger zerolog.Logger
+}
+
+// Authenticate identifies the caller and stores the user ID in c.Locals("user_id").
+// Requests carrying an "Authorization: Bearer" token are authenticated by verifying
+// the token; its subject becomes the user ID. Without a token the request is
+// rejected, unless trusted gateway mode is enabled and X-User-ID is set.
+//
+// Parameters:
+//   - cfg: The middleware configuration.
+//
+// Returns:
+//   - fiber.Handler: The middleware handler function.
+func Authenticate(cfg AuthConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		header := c.Get(fiber.HeaderAuthorization)
+		if token, ok := bearerToken(header); ok {
+			if cfg.Verifier == nil {
+				return unauthorized(c, "bearer tokens are not accepted")
+			}
+
+			claims, err := cfg.Verifier.Verify(token)
+			if err != nil {
+				cfg.Logger.Warn().Err(err).Str("path", c.Path()).Msg("rejected bearer token")
+				return unauthorized(c, "invalid bearer token")
+			}
+
+			if _, err := uuid.Parse(claims.Subject); err != nil {
+				cfg.Logger.Warn().Str("sub", claims.Subject).Msg("token subject is not a user ID")
+				return unauthorized(c, "invalid bearer token")
+			}
+
+			c.Locals("user_id", claims.Subject)
+			return c.Next()
+		}
+
+		if cfg.TrustedGateway && header == "" {
+			if userID := c.Get("X-User-ID"); userID != "" {
+				c.Locals("user_id", userID)
+				return c.Next()
+			}
+		}
+
+		return unauthorized(c, "authentication required")
+	}
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer <token>" header.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(c *fiber.Ctx, msg string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="template-service"`)
+	return fiber.NewError(fiber.StatusUnauthorized, msg)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Deprecated marks a route as deprecated. Responses carry a "Deprecation: true"
+// header and a Link header pointing to the route that replaces it.
+//
+// Parameters:
+//   - successor: The replacing route path; parameters such as ":id" are filled in
+//     from the request.
+//
+// Returns:
+//   - fiber.Handler: The middleware handler function.
+func Deprecated(successor string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		link := successor
+		for _, param := range c.Route().Params {
+			link = strings.ReplaceAll(link, ":"+param, c.Params(param))
+		}
+
+		c.Set("Deprecation", "true")
+		c.Set(fiber.HeaderLink, "<"+link+`>; rel="successor-version"`)
+		return c.Next()
+	}
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,7 +8,9 @@ import (
 )
 
 // RequestLogger logs details about incoming HTTP requests and their responses.
-// It logs the method, path, status, latency, and user ID (if available).
+// It logs the method, path, status, latency, and user ID (if available). Errors
+// returned by later handlers are written with the application's error handler
+// first, so that the logged status is the one sent to the client.
 //
 // Parameters:
 //   - logger: A zerolog.Logger instance for logging.
@@ -22,20 +23,14 @@ func RequestLogger(logger zerolog.Logger) fiber.Handler {
 		err := c.Next()
 		stop := time.Now()
 
-		status := c.Response().StatusCode()
-		msg := "request"
-
 		if err != nil {
-			var fe *fiber.Error
-			if errors.As(err, &fe) {
-				status = fe.Code
-				msg = fe.Message
-			} else {
-				status = fiber.StatusInternalServerError
-				msg = "Internal Server Error"
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
 			}
 		}
 
+		status := c.Response().StatusCode()
+
 		event := logger.Info()
 		if err != nil || status >= 400 {
 			event = logger.Error()
@@ -50,6 +45,10 @@ func RequestLogger(logger zerolog.Logger) fiber.Handler {
 			event = event.Str("user_id", userIDStr)
 		}
 
+		if requestID, ok := c.Locals("requestid").(string); ok {
+			event = event.Str("request_id", requestID)
+		}
+
 		event.
 			Str("method", c.Method()).
 			Str("path", c.Path()).
@@ -58,12 +57,6 @@ func RequestLogger(logger zerolog.Logger) fiber.Handler {
 			Str("ip", c.IP()).
 			Msg("request")
 
-		if err != nil {
-			return c.Status(status).JSON(fiber.Map{
-				"error": msg,
-			})
-		}
-
 		return nil
 	}
 }
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyStore persists the outcome of requests sent with an idempotency key.
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(owner, key, requestHash string, ttl time.Duration) (models.IdempotencyKey, bool, error)
+	CompleteIdempotencyKey(id uuid.UUID, status int, contentType string, body []byte) error
+	ReleaseIdempotencyKey(id uuid.UUID) error
+}
+
+// IdempotencyConfig configures the Idempotency middleware.
+type IdempotencyConfig struct {
+	// Store persists keys and responses.
+	Store IdempotencyStore
+
+	// TTL is how long a response is replayed for its key.
+	TTL time.Duration
+
+	// Logger records store failures.
+	Logger zerolog.Logger
+}
+
+// Idempotency makes a route safe to retry. The first request carrying an
+// "Idempotency-Key" header runs normally and its successful response is stored;
+// repeating the key replays that response with an "Idempotent-Replayed: true"
+// header. Reusing a key for a different request fails with 422, and repeating it
+// while the first request is still running fails with 409. Failed requests are not
+// stored, so that a retry runs them again. Requests without the header are not
+// affected.
+//
+// Keys belong to the authenticated user, or to the route path for share links,
+// so the middleware must run after authentication.
+//
+// Parameters:
+//   - cfg: The middleware configuration.
+//
+// Returns:
+//   - fiber.Handler: The middleware handler function.
+func Idempotency(cfg IdempotencyConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get("Idempotency-Key")
+		if key == "" {
+			return c.Next()
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key is too long")
+		}
+
+		owner, _ := c.Locals("user_id").(string)
+		if owner == "" {
+			owner = "path:" + c.Path()
+		}
+
+		hash := requestHash(c)
+		record, claimed, err := cfg.Store.ReserveIdempotencyKey(owner, key, hash, cfg.TTL)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			switch {
+			case record.RequestHash != hash:
+				return fiber.NewError(fiber.StatusUnprocessableEntity,
+					"Idempotency-Key was already used for a different request")
+			case record.Status == 0:
+				return fiber.NewError(fiber.StatusConflict,
+					"A request with this Idempotency-Key is still in progress")
+			}
+
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, record.ContentType)
+			return c.Status(record.Status).Send(record.Body)
+		}
+
+		if err := c.Next(); err != nil || c.Response().StatusCode() >= fiber.StatusInternalServerError {
+			release(cfg, record.ID, key)
+			return err
+		}
+
+		resp := c.Response()
+		if err := cfg.Store.CompleteIdempotencyKey(record.ID, resp.StatusCode(),
+			string(resp.Header.ContentType()), append([]byte(nil), resp.Body()...)); err != nil {
+			cfg.Logger.Error().Err(err).Str("key", key).Msg("failed to store idempotent response")
+			// A key left in progress would block every retry until it expires.
+			release(cfg, record.ID, key)
+		}
+
+		return nil
+	}
+}
+
+// requestHash fingerprints the method, path and body of a request.
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func release(cfg IdempotencyConfig, id uuid.UUID, key string) {
+	if err := cfg.Store.ReleaseIdempotencyKey(id); err != nil {
+		cfg.Logger.Error().Err(err).Str("key", key).Msg("failed to release idempotency key")
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory IdempotencyStore.
+type memoryStore struct {
+	records map[string]*models.IdempotencyKey
+}
+
+func (s *memoryStore) ReserveIdempotencyKey(owner, key, hash string, ttl time.Duration) (models.IdempotencyKey, bool, error) {
+	if r, ok := s.records[owner+"/"+key]; ok {
+		return *r, false, nil
+	}
+	r := &models.IdempotencyKey{ID: uuid.New(), Owner: owner, Key: key, RequestHash: hash}
+	s.records[owner+"/"+key] = r
+	return *r, true, nil
+}
+
+func (s *memoryStore) CompleteIdempotencyKey(id uuid.UUID, status int, contentType string, body []byte) error {
+	for _, r := range s.records {
+		if r.ID == id {
+			r.Status, r.ContentType, r.Body = status, contentType, body
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) ReleaseIdempotencyKey(id uuid.UUID) error {
+	for k, r := range s.records {
+		if r.ID == id {
+			delete(s.records, k)
+		}
+	}
+	return nil
+}
+
+// TestIdempotency verifies replay, key reuse and retries after failures.
+func TestIdempotency(t *testing.T) {
+	store := &memoryStore{records: map[string]*models.IdempotencyKey{}}
+	calls := 0
+
+	app := fiber.New()
+	app.Post("/items", Idempotency(IdempotencyConfig{Store: store, TTL: time.Hour, Logger: zerolog.Nop()}),
+		func(c *fiber.Ctx) error {
+			calls++
+			if string(c.Body()) == "fail" {
+				return fiber.ErrInternalServerError
+			}
+			return c.Status(fiber.StatusCreated).JSON(fiber.Map{"call": calls})
+		})
+
+	send := func(key, body string) (int, string, string) {
+		req := httptest.NewRequest(fiber.MethodPost, "/items", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		raw, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(raw), resp.Header.Get("Idempotent-Replayed")
+	}
+
+	status, body, replayed := send("k1", "a")
+	require.Equal(t, fiber.StatusCreated, status)
+	require.Equal(t, `{"call":1}`, body)
+	require.Empty(t, replayed)
+
+	status, body, replayed = send("k1", "a")
+	require.Equal(t, fiber.StatusCreated, status)
+	require.Equal(t, `{"call":1}`, body)
+	require.Equal(t, "true", replayed)
+	require.Equal(t, 1, calls)
+
+	status, _, _ = send("k1", "b")
+	require.Equal(t, fiber.StatusUnprocessableEntity, status)
+
+	status, _, _ = send("k2", "fail")
+	require.Equal(t, fiber.StatusInternalServerError, status)
+	require.NotContains(t, store.records, "path:/items/k2")
+
+	status, body, _ = send("", "a")
+	require.Equal(t, fiber.StatusCreated, status)
+	require.Equal(t, `{"call":3}`, body)
+}
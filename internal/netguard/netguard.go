@@ -0,0 +1,93 @@
+// Package netguard keeps outbound requests made on behalf of users, such as
+// webhook deliveries, away from the service's own host and internal networks.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// ErrBlockedAddress is returned for addresses that are not publicly routable.
+var ErrBlockedAddress = errors.New("address is not publicly routable")
+
+// blocked lists special-purpose ranges not covered by the netip predicates.
+var blocked = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),   // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which can reach IPv4 ranges
+}
+
+// Allowed reports whether ip is publicly routable: not loopback, private,
+// link-local, multicast, unspecified or otherwise reserved.
+func Allowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range blocked {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckHost resolves host and checks that every address it resolves to is
+// allowed. It is meant for validating input early; connections must still be
+// checked with Control, since DNS answers can change.
+//
+// Parameters:
+//   - ctx: Bounds the DNS lookup.
+//   - host: A host name or IP literal, without a port.
+//
+// Returns:
+//   - error: ErrBlockedAddress, or the lookup error if host cannot be resolved.
+func CheckHost(ctx context.Context, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !Allowed(ip) {
+			return fmt.Errorf("%s: %w", host, ErrBlockedAddress)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+
+	for _, ip := range addrs {
+		if !Allowed(ip) {
+			return fmt.Errorf("%s resolves to %s: %w", host, ip, ErrBlockedAddress)
+		}
+	}
+
+	return nil
+}
+
+// Control is a net.Dialer Control function that refuses to connect to
+// addresses that are not allowed. It runs after name resolution, for the exact
+// address dialled, so DNS rebinding cannot bypass it.
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, ErrBlockedAddress)
+	}
+
+	if !Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%s: %w", addrPort.Addr(), ErrBlockedAddress)
+	}
+
+	return nil
+}
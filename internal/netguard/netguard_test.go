@@ -0,0 +1,62 @@
+package netguard
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAllowed verifies that internal and reserved addresses are refused.
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1::1", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"255.255.255.255", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			require.Equal(t, tt.want, Allowed(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+// TestCheckHost verifies checks of IP literals and resolved names.
+func TestCheckHost(t *testing.T) {
+	require.NoError(t, CheckHost(context.Background(), "93.184.216.34"))
+	require.ErrorIs(t, CheckHost(context.Background(), "169.254.169.254"), ErrBlockedAddress)
+	require.ErrorIs(t, CheckHost(context.Background(), "localhost"), ErrBlockedAddress)
+}
+
+// TestControl verifies that dialing an internal address fails before connecting.
+func TestControl(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	dialer := net.Dialer{Timeout: time.Second, Control: Control}
+	_, err = dialer.Dial("tcp", listener.Addr().String())
+	require.ErrorIs(t, err, ErrBlockedAddress)
+}
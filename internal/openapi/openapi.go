@@ -0,0 +1,257 @@
+// Package openapi describes the HTTP API of the template service as an OpenAPI 3.0
+// document. Schemas are derived from the models package, so the document follows
+// the request and response types as they change.
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dashboard-platform/template-service/models"
+)
+
+// Version is the OpenAPI version the document conforms to.
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower case HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Security    []SecurityRequirement `json:"security"` // empty for public operations
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+}
+
+// SecurityRequirement maps a security scheme name to its required scopes.
+type SecurityRequirement map[string][]string
+
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]*Header   `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Parameters      map[string]*Parameter      `json:"parameters"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"` // http or apiKey
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+var spec = sync.OnceValue(build)
+
+// Spec returns the OpenAPI document of the service. It is built on first use and
+// shared afterwards, so callers must not modify it.
+func Spec() *Document {
+	return spec()
+}
+
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// Path converts a Fiber route path such as /templates/:id into its OpenAPI form,
+// /templates/{id}.
+func Path(route string) string {
+	return pathParam.ReplaceAllString(route, "{$1}")
+}
+
+// build assembles the document from the route table.
+func build() *Document {
+	g := newGenerator()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Template Service",
+			Description: "Stores, versions and renders HTML templates.",
+			Version:     "1.0.0",
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas:         g.schemas,
+			Parameters:      parameters,
+			SecuritySchemes: securitySchemes,
+		},
+	}
+	g.schema(typeOf[models.Problem]())
+
+	for _, r := range routes {
+		path := Path(r.path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(PathItem)
+		}
+		doc.Paths[path][strings.ToLower(r.method)] = r.operation(g)
+	}
+
+	return doc
+}
+
+// operation describes a route with the schemas of its body and response.
+func (r route) operation(g *generator) *Operation {
+	op := &Operation{
+		OperationID: r.id,
+		Summary:     r.summary,
+		Tags:        []string{r.tag},
+		Deprecated:  r.deprecated,
+		Security:    r.access.security(),
+		Responses:   make(map[string]*Response),
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(r.path, -1) {
+		schema := &Schema{Type: "string"}
+		if match[1] == "id" || match[1] == "userId" {
+			schema.Format = "uuid"
+		}
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   schema,
+		})
+	}
+	if r.access != public {
+		op.Parameters = append(op.Parameters, ref("OrgID"))
+	}
+	for _, header := range r.headers {
+		op.Parameters = append(op.Parameters, ref(header))
+	}
+	op.Parameters = append(op.Parameters, r.query...)
+
+	switch body := r.body.(type) {
+	case nil:
+	case *Schema:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{r.contentType(): {Schema: body}},
+		}
+	default:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{r.contentType(): {Schema: g.schemaOf(body)}},
+		}
+	}
+
+	status := r.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := &Response{Description: http.StatusText(status)}
+	if r.data != nil {
+		response.Content = map[string]MediaType{
+			"application/json": {Schema: envelope(g, r.data)},
+		}
+	}
+	if r.document != "" {
+		response.Content = map[string]MediaType{
+			r.document: {Schema: documentSchema(r.document)},
+		}
+	}
+	if r.etag {
+		response.Headers = map[string]*Header{
+			"ETag": {Description: "Entity tag of the template.", Schema: &Schema{Type: "string"}},
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = response
+	for _, extra := range r.responses {
+		op.Responses[strconv.Itoa(extra)] = &Response{Description: http.StatusText(extra)}
+	}
+
+	op.Responses["default"] = &Response{
+		Description: "Problem details of a failed request.",
+		Content: map[string]MediaType{
+			"application/problem+json": {Schema: &Schema{Ref: schemaRef + "Problem"}},
+		},
+	}
+
+	return op
+}
+
+func (r route) contentType() string {
+	if r.mediaType != "" {
+		return r.mediaType
+	}
+	return "application/json"
+}
+
+// envelope wraps response data in the models.Response object every handler returns.
+func envelope(g *generator, data map[string]any) *Schema {
+	members := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for name, value := range data {
+		members.Properties[name] = g.schemaOf(value)
+		members.Required = append(members.Required, name)
+	}
+	slices.Sort(members.Required)
+
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"error": {Type: "boolean"},
+			"data":  members,
+		},
+		Required: []string{"error", "data"},
+	}
+}
+
+// documentSchema returns the schema of an unwrapped response body.
+func documentSchema(mediaType string) *Schema {
+	if strings.HasSuffix(mediaType, "json") {
+		return &Schema{Type: "object"}
+	}
+	return &Schema{Type: "string", Format: "binary"}
+}
+
+func ref(parameter string) *Parameter {
+	return &Parameter{Ref: "#/components/parameters/" + parameter}
+}
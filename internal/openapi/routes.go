@@ -0,0 +1,580 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+)
+
+// access is the authentication a route accepts.
+type access int
+
+const (
+	user      access = iota // bearer token or trusted gateway
+	keyRead                 // user, or an API key with the read scope
+	keyRender               // user, or an API key with the render scope
+	public                  // no credentials, e.g. share links
+)
+
+func (a access) security() []SecurityRequirement {
+	requirements := []SecurityRequirement{}
+	if a == public {
+		return requirements
+	}
+
+	requirements = append(requirements,
+		SecurityRequirement{"bearerAuth": {}},
+		SecurityRequirement{"gatewayAuth": {}},
+	)
+	if a == keyRead || a == keyRender {
+		requirements = append(requirements, SecurityRequirement{"apiKeyAuth": {}})
+	}
+	return requirements
+}
+
+// route documents one route registered in cmd/main.go.
+type route struct {
+	method     string
+	path       string // Fiber syntax, e.g. /templates/:id
+	id         string
+	summary    string
+	tag        string
+	access     access
+	deprecated bool
+	headers    []string // names of shared header parameters
+	query      []*Parameter
+	body       any    // zero value of the request body type, or a *Schema
+	mediaType  string // defaults to application/json
+	status     int    // success status, defaults to 200
+	data       map[string]any
+	document   string // media type of a response object not wrapped in models.Response
+	etag       bool   // the response carries an ETag header
+	responses  []int  // other statuses without a body
+}
+
+var securitySchemes = map[string]*SecurityScheme{
+	"bearerAuth": {
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	},
+	"apiKeyAuth": {
+		Type:        "apiKey",
+		In:          "header",
+		Name:        "Authorization",
+		Description: `An API key sent as "ApiKey <key>". Keys are limited to the routes that accept their scopes.`,
+	},
+	"gatewayAuth": {
+		Type:        "apiKey",
+		In:          "header",
+		Name:        "X-User-ID",
+		Description: "The caller's user ID, accepted only behind a trusted gateway.",
+	},
+}
+
+var parameters = map[string]*Parameter{
+	"OrgID": {
+		Name:        "X-Org-ID",
+		In:          "header",
+		Description: "Organization to act in; personal templates when omitted.",
+		Schema:      &Schema{Type: "string", Format: "uuid"},
+	},
+	"IfMatch": {
+		Name:        "If-Match",
+		In:          "header",
+		Description: "Entity tag the template must still have.",
+		Schema:      &Schema{Type: "string"},
+	},
+	"IfNoneMatch": {
+		Name:        "If-None-Match",
+		In:          "header",
+		Description: "Entity tags the client already holds.",
+		Schema:      &Schema{Type: "string"},
+	},
+	"IdempotencyKey": {
+		Name:        "Idempotency-Key",
+		In:          "header",
+		Description: "Replays the first response to retries with the same key and body. Renders are deduplicated on the render endpoints, which record their own history entries.",
+		Schema:      &Schema{Type: "string"},
+	},
+}
+
+// query describes an optional query parameter. typ is a JSON type, or uuid or
+// date-time for formatted strings.
+func query(name, typ, description string) *Parameter {
+	schema := &Schema{Type: typ}
+	switch typ {
+	case "uuid", "date-time":
+		schema = &Schema{Type: "string", Format: typ}
+	}
+	return &Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var (
+	page = []*Parameter{
+		query("cursor", "string", "Cursor from the next_cursor of the previous page."),
+		query("limit", "integer", "Maximum number of entries."),
+	}
+	historyQuery = append([]*Parameter{
+		query("version", "integer", "Only renders of this version."),
+		query("from", "date-time", "Renders at or after this time."),
+		query("to", "date-time", "Renders before this time."),
+	}, page...)
+	statsQuery = []*Parameter{
+		query("bucket", "string", "Bucket size: hour, day or week."),
+		query("from", "date-time", "Start of the range."),
+		query("to", "date-time", "End of the range."),
+		query("limit", "integer", "Maximum number of top templates."),
+	}
+	nextCursor = (*string)(nil)
+)
+
+// templatePatch is the JSON Merge Patch accepted by PATCH /templates/:id. Fields
+// are keyed by their key; null removes a field.
+var templatePatch = &Schema{
+	Type: "object",
+	Properties: map[string]*Schema{
+		"name":        {Type: "string"},
+		"description": {Type: "string"},
+		"catalog_id":  {Type: "string", Format: "uuid", Nullable: true},
+		"fields": {
+			Type: "object",
+			AdditionalProperties: &Schema{
+				Type:     "object",
+				Nullable: true,
+				Properties: map[string]*Schema{
+					"label":    {Type: "string"},
+					"type":     {Type: "string"},
+					"required": {Type: "boolean"},
+					"options":  {},
+				},
+			},
+		},
+	},
+}
+
+var routes = []route{
+	{
+		method: http.MethodGet, path: "/openapi.json", id: "getOpenAPI", tag: "meta", access: public,
+		summary:  "This document.",
+		document: "application/json",
+	},
+
+	{
+		method: http.MethodPost, path: "/templates", id: "createTemplate", tag: "templates",
+		summary: "Create a template.",
+		headers: []string{"IdempotencyKey"},
+		body:    models.CreateTemplateAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"id": uuid.UUID{}, "name": ""},
+	},
+	{
+		method: http.MethodGet, path: "/templates", id: "listTemplates", tag: "templates", access: keyRead,
+		summary: "List templates visible to the caller.",
+		query: append([]*Parameter{
+			query("type", "string", "Template type."),
+			query("category", "string", "Template category."),
+			query("tag", "string", "Tag name."),
+			query("folder", "uuid", "Folder ID."),
+			query("is_public", "boolean", "Published to the gallery."),
+			query("created_after", "date-time", ""),
+			query("created_before", "date-time", ""),
+			query("updated_after", "date-time", ""),
+			query("updated_before", "date-time", ""),
+			query("sort", "string", "name or updated_at, prefixed with - for descending order."),
+		}, page...),
+		data: map[string]any{"templates": []models.TemplateSummaryDTO{}, "next_cursor": nextCursor},
+	},
+	{
+		method: http.MethodGet, path: "/templates/export", id: "exportTemplates", tag: "bundles", access: keyRead,
+		summary:  "Export every template of the workspace as a bundle.",
+		document: "application/zip",
+	},
+	{
+		method: http.MethodPost, path: "/templates/import", id: "importTemplates", tag: "bundles",
+		summary:   "Create the templates of a bundle in the workspace.",
+		headers:   []string{"IdempotencyKey"},
+		body:      &Schema{Type: "string", Format: "binary"},
+		mediaType: "application/zip",
+		status:    http.StatusCreated,
+		data:      map[string]any{"import": models.ImportResultDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/history", id: "listHistory", tag: "history",
+		summary: "List the caller's renders.",
+		query:   append([]*Parameter{query("template_id", "uuid", "Only renders of this template.")}, historyQuery...),
+		data:    map[string]any{"history": []models.HistoryDTO{}, "next_cursor": nextCursor},
+	},
+	{
+		method: http.MethodPost, path: "/templates/history", id: "createHistory", tag: "history", deprecated: true,
+		summary: "Removed. Renders are recorded by POST /templates/{id}/preview and POST /shared/{token}/render, " +
+			"which accept Idempotency-Key.",
+		status: http.StatusGone,
+	},
+	{
+		method: http.MethodPost, path: "/templates/history/:id/rerender", id: "rerenderHistory", tag: "history",
+		summary: "Render a history entry again and verify its output hash.",
+		data:    map[string]any{"output": "", "output_hash": "", "history": models.HistoryDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/search", id: "searchTemplates", tag: "templates", access: keyRead,
+		summary: "Search templates by name, description and content.",
+		query: []*Parameter{
+			query("q", "string", "Search terms."),
+			query("limit", "integer", "Maximum number of results."),
+		},
+		data: map[string]any{"results": []models.TemplateSearchResultDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/recent", id: "getRecentTemplates", tag: "templates",
+		summary: "Templates the caller rendered recently and most often.",
+		query: []*Parameter{
+			query("days", "integer", "Window of the frequent list."),
+			query("limit", "integer", "Maximum number of templates per list."),
+		},
+		data: map[string]any{"recent": []models.TemplateUsageDTO{}, "frequent": []models.TemplateUsageDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/favorites", id: "getFavorites", tag: "templates",
+		summary: "The caller's favorite templates.",
+		data:    map[string]any{"templates": []models.TemplateSummaryDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/:id", id: "getTemplate", tag: "templates", access: keyRead,
+		summary:   "Get a template with its fields and latest version.",
+		headers:   []string{"IfNoneMatch"},
+		data:      map[string]any{"template": models.TemplateDTO{}},
+		etag:      true,
+		responses: []int{http.StatusNotModified},
+	},
+	{
+		method: http.MethodPut, path: "/templates/:id", id: "replaceTemplate", tag: "templates",
+		summary: "Replace a template. Changed content is published as a new version.",
+		headers: []string{"IfMatch"},
+		body:    models.CreateTemplateAPI{},
+		status:  http.StatusNoContent,
+		etag:    true,
+	},
+	{
+		method: http.MethodPatch, path: "/templates/:id", id: "patchTemplate", tag: "templates",
+		summary:   "Apply a JSON Merge Patch to the metadata and fields of a template.",
+		headers:   []string{"IfMatch"},
+		body:      templatePatch,
+		mediaType: "application/merge-patch+json",
+		status:    http.StatusNoContent,
+		etag:      true,
+	},
+	{
+		method: http.MethodDelete, path: "/templates/:id", id: "deleteTemplate", tag: "templates",
+		summary: "Delete a template.",
+		headers: []string{"IfMatch"},
+		status:  http.StatusNoContent,
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/update", id: "updateTemplate", tag: "templates", deprecated: true,
+		summary: "Use PUT /templates/{id}.",
+		headers: []string{"IfMatch"},
+		body:    models.CreateTemplateAPI{},
+		etag:    true,
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/delete", id: "deleteTemplateLegacy", tag: "templates", deprecated: true,
+		summary: "Use DELETE /templates/{id}.",
+		headers: []string{"IfMatch"},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/publish", id: "publishTemplate", tag: "gallery",
+		summary: "Publish a template to the gallery.",
+		data:    map[string]any{"id": "", "is_public": false},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/unpublish", id: "unpublishTemplate", tag: "gallery",
+		summary: "Remove a template from the gallery.",
+		data:    map[string]any{"id": "", "is_public": false},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/clone", id: "cloneTemplate", tag: "templates",
+		summary: "Fork a template into the caller's scope.",
+		body:    models.CloneTemplateAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"template": models.TemplateDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/:id/export", id: "exportTemplate", tag: "bundles", access: keyRead,
+		summary:  "Export a template with every version as a bundle.",
+		document: "application/zip",
+	},
+	{
+		method: http.MethodGet, path: "/templates/:id/schema", id: "getTemplateSchema", tag: "templates", access: keyRead,
+		summary:   "JSON Schema (draft 2020-12) of the values a template accepts.",
+		headers:   []string{"IfNoneMatch"},
+		document:  "application/schema+json",
+		etag:      true,
+		responses: []int{http.StatusNotModified},
+	},
+	{
+		method: http.MethodGet, path: "/templates/:id/history", id: "getTemplateHistory", tag: "history",
+		summary: "List the renders of a template.",
+		query:   historyQuery,
+		data:    map[string]any{"history": []models.HistoryDTO{}, "next_cursor": nextCursor},
+	},
+	{
+		method: http.MethodGet, path: "/templates/:id/stats", id: "getTemplateStats", tag: "stats",
+		summary: "Render statistics of a template.",
+		query:   statsQuery,
+		data:    map[string]any{"stats": models.StatsDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/:id/upstream", id: "getUpstream", tag: "templates", access: keyRead,
+		summary: "Compare a fork with the latest version of its source.",
+		data:    map[string]any{"upstream": models.UpstreamDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/upstream/pull", id: "pullUpstream", tag: "templates",
+		summary: "Merge the source's latest version into a fork.",
+		data:    map[string]any{"version": models.TemplateVersionDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/shares", id: "grantShare", tag: "shares",
+		summary: "Share a template with a user.",
+		body:    models.ShareAPI{},
+		data:    map[string]any{"share": models.ShareDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/templates/:id/shares", id: "getShares", tag: "shares",
+		summary: "List the users a template is shared with.",
+		data:    map[string]any{"shares": []models.ShareDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/shares/:userId/delete", id: "revokeShare", tag: "shares",
+		summary: "Stop sharing a template with a user.",
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/preview", id: "previewTemplate", tag: "render", access: keyRender,
+		summary: "Render the latest version of a template.",
+		headers: []string{"IdempotencyKey"},
+		body:    models.RenderAPI{},
+		data:    map[string]any{"preview_html": ""},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/share-links", id: "createShareLink", tag: "shares",
+		summary: "Create a signed link to view or render a template version.",
+		body:    models.CreateShareLinkAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"share_link": models.ShareLinkDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/tags", id: "setTemplateTags", tag: "tags",
+		summary: "Replace the tags of a template.",
+		body:    models.TemplateTagsAPI{},
+		data:    map[string]any{"tags": []models.TagDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/move", id: "moveTemplate", tag: "folders",
+		summary: "Move a template into a folder, or to the root.",
+		body:    models.MoveTemplateAPI{},
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/favorite", id: "favoriteTemplate", tag: "templates",
+		summary: "Add a template to the caller's favorites.",
+	},
+	{
+		method: http.MethodPost, path: "/templates/:id/unfavorite", id: "unfavoriteTemplate", tag: "templates",
+		summary: "Remove a template from the caller's favorites.",
+	},
+	{
+		method: http.MethodGet, path: "/shared/:token", id: "getSharedTemplate", tag: "shares", access: public,
+		summary: "Get the template version behind a share link.",
+		data:    map[string]any{"template": models.SharedTemplateDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/shared/:token/render", id: "renderSharedTemplate", tag: "render", access: public,
+		summary: "Render the template version behind a share link.",
+		headers: []string{"IdempotencyKey"},
+		body:    models.RenderAPI{},
+		data:    map[string]any{"preview_html": ""},
+	},
+
+	{
+		method: http.MethodPost, path: "/catalogs", id: "createCatalog", tag: "catalogs",
+		summary: "Create a message catalog.",
+		body:    models.CatalogAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"catalog": models.CatalogDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/catalogs", id: "listCatalogs", tag: "catalogs", access: keyRead,
+		summary: "List message catalogs.",
+		data:    map[string]any{"catalogs": []models.CatalogDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/catalogs/:id", id: "getCatalog", tag: "catalogs", access: keyRead,
+		summary: "Get a message catalog.",
+		data:    map[string]any{"catalog": models.CatalogDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/catalogs/:id/update", id: "updateCatalog", tag: "catalogs",
+		summary: "Replace a message catalog.",
+		body:    models.CatalogAPI{},
+	},
+	{
+		method: http.MethodPost, path: "/catalogs/:id/delete", id: "deleteCatalog", tag: "catalogs",
+		summary: "Delete a message catalog.",
+	},
+
+	{
+		method: http.MethodPost, path: "/tags", id: "createTag", tag: "tags",
+		summary: "Create a tag.",
+		body:    models.TagAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"tag": models.TagDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/tags", id: "listTags", tag: "tags", access: keyRead,
+		summary: "List tags.",
+		data:    map[string]any{"tags": []models.TagDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/tags/:id/delete", id: "deleteTag", tag: "tags",
+		summary: "Delete a tag.",
+	},
+
+	{
+		method: http.MethodPost, path: "/folders", id: "createFolder", tag: "folders",
+		summary: "Create a folder.",
+		body:    models.FolderAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"folder": models.FolderDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/folders", id: "listFolders", tag: "folders", access: keyRead,
+		summary: "List folders.",
+		data:    map[string]any{"folders": []models.FolderDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/folders/:id/update", id: "updateFolder", tag: "folders",
+		summary: "Rename a folder and move it under a new parent.",
+		body:    models.FolderAPI{},
+	},
+	{
+		method: http.MethodPost, path: "/folders/:id/delete", id: "deleteFolder", tag: "folders",
+		summary: "Delete an empty folder.",
+	},
+
+	{
+		method: http.MethodGet, path: "/stats/usage", id: "getUsageStats", tag: "stats",
+		summary: "Render statistics across the caller's templates.",
+		query:   statsQuery,
+		data:    map[string]any{"stats": models.StatsDTO{}},
+	},
+
+	{
+		method: http.MethodGet, path: "/retention", id: "getRetentionPolicy", tag: "retention",
+		summary: "The history retention policy in effect.",
+		data:    map[string]any{"policy": models.RetentionPolicyDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/retention", id: "setRetentionPolicy", tag: "retention",
+		summary: "Set the organization's history retention policy.",
+		body:    models.RetentionPolicyAPI{},
+		data:    map[string]any{"policy": models.RetentionPolicyDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/retention/delete", id: "deleteRetentionPolicy", tag: "retention",
+		summary: "Fall back to the global retention policy.",
+	},
+	{
+		method: http.MethodGet, path: "/retention/dry-run", id: "previewHistoryPurge", tag: "retention",
+		summary: "History entries the next purge would delete.",
+		data:    map[string]any{"purge": models.PurgeReportDTO{}},
+	},
+
+	{
+		method: http.MethodGet, path: "/gallery", id: "listGallery", tag: "gallery",
+		summary: "List published templates.",
+		query: []*Parameter{
+			query("category", "string", "Template category."),
+			query("type", "string", "Template type."),
+			query("page", "integer", "Page number, starting at 1."),
+			query("limit", "integer", "Templates per page."),
+		},
+		data: map[string]any{"templates": []models.TemplateDTO{}, "page": 0, "limit": 0, "total": int64(0)},
+	},
+	{
+		method: http.MethodGet, path: "/gallery/:id", id: "getGalleryTemplate", tag: "gallery",
+		summary: "Get a published template.",
+		data:    map[string]any{"template": models.TemplateDTO{}},
+	},
+
+	{
+		method: http.MethodPost, path: "/orgs", id: "createOrganization", tag: "orgs",
+		summary: "Create an organization owned by the caller.",
+		body:    models.CreateOrganizationAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"organization": models.OrganizationDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/orgs", id: "listOrganizations", tag: "orgs",
+		summary: "List the caller's organizations.",
+		data:    map[string]any{"organizations": []models.OrganizationDTO{}},
+	},
+	{
+		method: http.MethodGet, path: "/orgs/:id/members", id: "getOrgMembers", tag: "orgs",
+		summary: "List the members of an organization.",
+		data:    map[string]any{"members": []models.OrgMemberDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/orgs/:id/members", id: "setOrgMember", tag: "orgs",
+		summary: "Add a member or change their role.",
+		body:    models.OrgMemberAPI{},
+		data:    map[string]any{"member": models.OrgMemberDTO{}},
+	},
+	{
+		method: http.MethodPost, path: "/orgs/:id/members/:userId/delete", id: "removeOrgMember", tag: "orgs",
+		summary: "Remove a member from an organization.",
+	},
+
+	{
+		method: http.MethodPost, path: "/api-keys", id: "createAPIKey", tag: "api-keys",
+		summary: "Create an API key. The key is only returned once.",
+		body:    models.CreateAPIKeyAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"api_key": models.APIKeyDTO{}, "key": ""},
+	},
+	{
+		method: http.MethodGet, path: "/api-keys", id: "listAPIKeys", tag: "api-keys",
+		summary: "List the caller's API keys.",
+		data:    map[string]any{"api_keys": []models.APIKeyDTO{}},
+	},
+	{
+		method: http.MethodDelete, path: "/api-keys/:id", id: "deleteAPIKey", tag: "api-keys",
+		summary: "Revoke an API key.",
+	},
+
+	{
+		method: http.MethodPost, path: "/webhooks", id: "createWebhook", tag: "webhooks",
+		summary: "Register a webhook for template events. The signing secret is only returned once.",
+		body:    models.WebhookAPI{},
+		status:  http.StatusCreated,
+		data:    map[string]any{"webhook": models.WebhookDTO{}, "secret": ""},
+	},
+	{
+		method: http.MethodGet, path: "/webhooks", id: "listWebhooks", tag: "webhooks",
+		summary: "List the webhooks of the workspace.",
+		data:    map[string]any{"webhooks": []models.WebhookDTO{}},
+	},
+	{
+		method: http.MethodDelete, path: "/webhooks/:id", id: "deleteWebhook", tag: "webhooks",
+		summary: "Delete a webhook. Its pending deliveries fail.",
+	},
+	{
+		method: http.MethodGet, path: "/webhooks/:id/deliveries", id: "listWebhookDeliveries", tag: "webhooks",
+		summary: "List the most recent deliveries of a webhook.",
+		query: []*Parameter{
+			query("status", "string", "pending, succeeded or failed."),
+			query("limit", "integer", "Maximum number of deliveries."),
+		},
+		data: map[string]any{"deliveries": []models.WebhookDeliveryDTO{}},
+	},
+}
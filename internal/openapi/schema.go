@@ -0,0 +1,162 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const schemaRef = "#/components/schemas/"
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+var (
+	timeType    = typeOf[time.Time]()
+	uuidType    = typeOf[uuid.UUID]()
+	rawJSONType = typeOf[json.RawMessage]()
+	gormJSON    = typeOf[datatypes.JSON]()
+)
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// generator derives schemas from Go types. Named structs are added to the
+// components once and referenced from everywhere they are used.
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func newGenerator() *generator {
+	return &generator{schemas: make(map[string]*Schema)}
+}
+
+// schemaOf returns the schema of the type of value. A typed nil pointer such as
+// (*string)(nil) describes a nullable member.
+func (g *generator) schemaOf(value any) *Schema {
+	return g.schema(reflect.TypeOf(value))
+}
+
+// schema returns the schema of t as encoding/json marshals it.
+//
+// Parameters:
+//   - t: The Go type to describe.
+//
+// Returns:
+//   - *Schema: An inline schema, or a reference for named structs.
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawJSONType, gormJSON:
+		return &Schema{} // any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schema(t.Elem())
+		if s.Ref != "" {
+			// Siblings of $ref are ignored, so the reference is wrapped.
+			return &Schema{AllOf: []*Schema{s}, Nullable: true}
+		}
+		s.Nullable = true
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// Register before describing the fields, so recursive types terminate.
+			s := &Schema{}
+			g.schemas[t.Name()] = s
+			*s = *g.object(t)
+		}
+		return &Schema{Ref: schemaRef + t.Name()}
+	}
+
+	return &Schema{}
+}
+
+// object describes the members of a struct. Request types, named *API by
+// convention, require the fields bound as required; other types require every
+// field that is not omitted when empty. A doc tag describes its field.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.fields(s, t, strings.HasSuffix(t.Name(), "API"))
+	return s
+}
+
+func (g *generator) fields(s *Schema, t reflect.Type, request bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a name are flattened, as encoding/json does.
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(s, ft, request)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = g.schema(f.Type)
+		if doc := f.Tag.Get("doc"); doc != "" {
+			s.Properties[name].Description = doc
+		}
+
+		required := !strings.Contains(opts, "omitempty")
+		if request {
+			required = f.Tag.Get("binding") == "required"
+		}
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
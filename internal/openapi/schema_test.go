@@ -0,0 +1,116 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type embeddedDTO struct {
+	ID uuid.UUID `json:"id"`
+}
+
+type sampleDTO struct {
+	embeddedDTO
+	Name     string          `json:"name" doc:"Display name."`
+	Note     string          `json:"note,omitempty"`
+	Parent   *embeddedDTO    `json:"parent,omitempty"`
+	At       *time.Time      `json:"at"`
+	Options  json.RawMessage `json:"options,omitempty"`
+	Data     []byte          `json:"data"`
+	Labels   map[string]int  `json:"labels"`
+	Children []sampleDTO     `json:"children"`
+	Hidden   string          `json:"-"`
+	internal string
+}
+
+type sampleAPI struct {
+	Name  string `json:"name" binding:"required"`
+	Limit int    `json:"limit"`
+}
+
+func TestGeneratorSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      reflect.Type
+		expected *Schema
+	}{
+		{"time", timeType, &Schema{Type: "string", Format: "date-time"}},
+		{"uuid", uuidType, &Schema{Type: "string", Format: "uuid"}},
+		{"nullable string", typeOf[*string](), &Schema{Type: "string", Nullable: true}},
+		{"bytes", typeOf[[]byte](), &Schema{Type: "string", Format: "byte"}},
+		{"raw json", rawJSONType, &Schema{}},
+		{"any map", typeOf[map[string]any](), &Schema{Type: "object", AdditionalProperties: &Schema{}}},
+		{"int64", typeOf[int64](), &Schema{Type: "integer", Format: "int64"}},
+		{"named struct", typeOf[sampleAPI](), &Schema{Ref: schemaRef + "sampleAPI"}},
+		{"nullable struct", typeOf[*sampleAPI](), &Schema{AllOf: []*Schema{{Ref: schemaRef + "sampleAPI"}}, Nullable: true}},
+		{"array", typeOf[[]string](), &Schema{Type: "array", Items: &Schema{Type: "string"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, newGenerator().schema(tt.typ))
+		})
+	}
+}
+
+func TestGeneratorObject(t *testing.T) {
+	g := newGenerator()
+	g.schema(typeOf[sampleDTO]())
+
+	dto := g.schemas["sampleDTO"]
+	require.NotNil(t, dto)
+	require.ElementsMatch(t, []string{"id", "name", "note", "parent", "at", "options", "data", "labels", "children"},
+		keys(dto.Properties))
+	require.Equal(t, []string{"id", "name", "at", "data", "labels", "children"}, dto.Required)
+	require.Equal(t, &Schema{Ref: schemaRef + "sampleDTO"}, dto.Properties["children"].Items)
+	require.Equal(t, &Schema{Type: "string", Description: "Display name."}, dto.Properties["name"])
+	require.Contains(t, g.schemas, "embeddedDTO")
+
+	g.schema(typeOf[sampleAPI]())
+	require.Equal(t, []string{"name"}, g.schemas["sampleAPI"].Required)
+}
+
+var refPattern = regexp.MustCompile(`"\$ref":"#/components/(\w+)/(\w+)"`)
+
+func TestSpecReferences(t *testing.T) {
+	spec := Spec()
+	data, err := json.Marshal(spec)
+	require.NoError(t, err)
+
+	for _, match := range refPattern.FindAllStringSubmatch(string(data), -1) {
+		switch match[1] {
+		case "schemas":
+			require.Contains(t, spec.Components.Schemas, match[2])
+		case "parameters":
+			require.Contains(t, spec.Components.Parameters, match[2])
+		default:
+			t.Fatalf("unexpected reference %s", match[0])
+		}
+	}
+
+	ids := make(map[string]bool)
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			require.False(t, ids[op.OperationID], "duplicate operation ID %s at %s %s", op.OperationID, method, path)
+			ids[op.OperationID] = true
+		}
+	}
+	require.Contains(t, spec.Components.Schemas, "CreateTemplateAPI")
+	require.Contains(t, spec.Components.Schemas, "TemplateDTO")
+	require.Contains(t, spec.Components.Schemas, "FieldDTO")
+	require.Contains(t, spec.Components.Schemas, "HistoryDTO")
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+// Package render executes template content against caller-supplied values.
+// It wraps the Handlebars engine and registers the helpers available to templates.
+package render
+
+import (
+	"errors"
+
+	"github.com/aymerick/raymond"
+	"github.com/dashboard-platform/template-service/internal/i18n"
+)
+
+// Options configures a single render.
+type Options struct {
+	// Translator resolves {{t "key"}} lookups. When nil, keys are rendered as-is.
+	Translator *i18n.Translator
+}
+
+// Render parses content as a Handlebars template and executes it with values.
+//
+// Parameters:
+//   - content: The template source.
+//   - values: The data the template is executed against.
+//   - opts: Render options such as the translator for the t helper.
+//
+// Returns:
+//   - string: The rendered output.
+//   - error: An error if parsing or execution fails.
+func Render(content string, values map[string]any, opts Options) (string, error) {
+	tpl, err := raymond.Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	tpl.RegisterHelper("t", translateHelper(opts.Translator))
+
+	return tpl.Exec(values)
+}
+
+// translateHelper builds the {{t "key" name=value}} helper. Hash arguments are
+// passed to the message as placeholder values; unknown keys render as the key itself.
+func translateHelper(tr *i18n.Translator) func(string, *raymond.Options) string {
+	return func(key string, options *raymond.Options) string {
+		if tr == nil {
+			return key
+		}
+
+		out, err := tr.Translate(key, options.Hash())
+		if errors.Is(err, i18n.ErrMissingMessage) {
+			return key
+		}
+		if err != nil {
+			// raymond turns panics with an error value into an Exec error.
+			panic(err)
+		}
+
+		return out
+	}
+}
@@ -0,0 +1,101 @@
+// Package retention runs the background jobs that purge render history entries
+// expired by the global and per-organization retention rules, and that clean up
+// expired idempotency records and webhook deliveries.
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/rs/zerolog"
+)
+
+// batchSize is the maximum number of history entries deleted per statement.
+const batchSize = 1000
+
+// Purger deletes expired history entries, idempotency records and webhook deliveries.
+type Purger interface {
+	PurgeHistory(global database.RetentionRules, batchSize int) (int64, error)
+	PurgeIdempotencyKeys() (int64, error)
+	PurgeWebhookDeliveries() (int64, error)
+}
+
+// Intervals holds the time between runs of each job. A non-positive interval
+// disables its job.
+type Intervals struct {
+	History time.Duration // Purges of expired history entries.
+	Cleanup time.Duration // Cleanups of expired idempotency records and webhook deliveries.
+}
+
+// Start runs the history purge and the cleanup of expired records, each at its
+// own interval, until ctx is cancelled. The first run of each job starts
+// immediately.
+//
+// Parameters:
+//   - ctx: Stops the jobs when cancelled.
+//   - purger: Deletes the expired entries.
+//   - intervals: The time between runs of each job.
+//   - global: The rules for organizations without a policy of their own.
+//   - logger: Records purge results and failures.
+func Start(ctx context.Context, purger Purger, intervals Intervals, global database.RetentionRules, logger zerolog.Logger) {
+	if intervals.History <= 0 {
+		logger.Info().Msg("history purging disabled")
+	} else {
+		go every(ctx, intervals.History, func() { purgeHistory(purger, global, logger) })
+	}
+
+	if intervals.Cleanup <= 0 {
+		logger.Info().Msg("expired record cleanup disabled")
+	} else {
+		go every(ctx, intervals.Cleanup, func() { cleanup(purger, logger) })
+	}
+}
+
+// every calls run immediately and then every interval until ctx is cancelled.
+func every(ctx context.Context, interval time.Duration, run func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeHistory(purger Purger, global database.RetentionRules, logger zerolog.Logger) {
+	start := time.Now()
+
+	deleted, err := purger.PurgeHistory(global, batchSize)
+	if err != nil {
+		logger.Error().Err(err).Int64("deleted", deleted).Msg("history purge failed")
+		return
+	}
+
+	logger.Info().
+		Int64("deleted", deleted).
+		Dur("duration", time.Since(start)).
+		Msg("history purge finished")
+}
+
+// cleanup deletes expired records. Each cleanup runs even if another fails.
+func cleanup(purger Purger, logger zerolog.Logger) {
+	keys, err := purger.PurgeIdempotencyKeys()
+	if err != nil {
+		logger.Error().Err(err).Msg("idempotency key purge failed")
+	} else {
+		logger.Debug().Int64("deleted", keys).Msg("idempotency key purge finished")
+	}
+
+	deliveries, err := purger.PurgeWebhookDeliveries()
+	if err != nil {
+		logger.Error().Err(err).Msg("webhook delivery purge failed")
+	} else {
+		logger.Debug().Int64("deleted", deliveries).Msg("webhook delivery purge finished")
+	}
+}
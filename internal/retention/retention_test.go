@@ -0,0 +1,39 @@
+package retention
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// failingPurger fails every purge and records which ones ran.
+type failingPurger struct {
+	calls []string
+}
+
+func (p *failingPurger) PurgeHistory(database.RetentionRules, int) (int64, error) {
+	p.calls = append(p.calls, "history")
+	return 0, errors.New("unavailable")
+}
+
+func (p *failingPurger) PurgeIdempotencyKeys() (int64, error) {
+	p.calls = append(p.calls, "idempotency")
+	return 0, errors.New("unavailable")
+}
+
+func (p *failingPurger) PurgeWebhookDeliveries() (int64, error) {
+	p.calls = append(p.calls, "webhooks")
+	return 0, errors.New("unavailable")
+}
+
+// TestCleanup verifies that a failing cleanup does not skip the ones after it.
+func TestCleanup(t *testing.T) {
+	purger := &failingPurger{}
+
+	cleanup(purger, zerolog.Nop())
+
+	require.Equal(t, []string{"idempotency", "webhooks"}, purger.calls)
+}
@@ -0,0 +1,260 @@
+// Package webhook delivers template events to the endpoints registered for them.
+// Events are taken from the outbox the database writes along with each change,
+// signed with the endpoint's secret, and retried with exponential backoff until
+// the endpoint accepts them or the attempts run out.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/internal/netguard"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Headers sent with every delivery.
+const (
+	SignatureHeader = "Webhook-Signature" // t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+	EventHeader     = "Webhook-Event"     // The event name, e.g. template.created.
+	DeliveryHeader  = "Webhook-Id"        // The delivery ID, the same on every retry.
+)
+
+// MaxAttempts is the number of times a delivery is sent before it fails.
+const MaxAttempts = 10
+
+const (
+	baseDelay = 30 * time.Second // delay after the first failed attempt
+	maxDelay  = 6 * time.Hour    // upper bound of the delay between attempts
+
+	eventBatch    = 100              // outbox events fanned out per query
+	deliveryBatch = 20               // deliveries claimed and sent concurrently
+	timeout       = 10 * time.Second // time an endpoint has to respond
+	lease         = time.Minute      // time a claimed delivery is reserved for
+	maxErrorLen   = 500              // length of errors kept in the delivery log
+)
+
+// Store reads the outbox and tracks deliveries.
+type Store interface {
+	FanOutWebhookEvents(limit int) (int, error)
+	ClaimWebhookDeliveries(limit int, lease time.Duration) ([]database.DueDelivery, error)
+	RecordWebhookAttempt(id uuid.UUID, attempt database.DeliveryAttempt) error
+}
+
+// Sign returns the signature header value of a delivery body. Receivers recompute
+// the HMAC over the timestamp and the raw body, and should reject stale timestamps
+// to prevent replays.
+//
+// Parameters:
+//   - secret: The webhook's signing secret.
+//   - timestamp: The time the delivery is sent.
+//   - body: The request body.
+//
+// Returns:
+//   - string: The signature in the form t=<unix time>,v1=<hex digest>.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before retrying a delivery that failed attempt
+// times. It doubles with every attempt and is capped at maxDelay.
+func Backoff(attempt int) time.Duration {
+	delay := baseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return delay
+}
+
+type dispatcher struct {
+	store  Store
+	client *http.Client
+	logger zerolog.Logger
+}
+
+func newDispatcher(store Store, logger zerolog.Logger) *dispatcher {
+	return &dispatcher{
+		store: store,
+		client: &http.Client{
+			Timeout: timeout,
+			// Endpoints are user supplied; refuse connections to internal addresses
+			// at dial time, after DNS resolution, and never go through a proxy
+			// that would dial on our behalf.
+			Transport: &http.Transport{
+				DialContext:         (&net.Dialer{Timeout: timeout, Control: netguard.Control}).DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConns:        100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+			// A redirect is not an acknowledgement; endpoints must be registered
+			// with their final URL.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		logger: logger,
+	}
+}
+
+// Start dispatches webhook deliveries every interval until ctx is cancelled.
+// The first run starts immediately. A non-positive interval disables delivery;
+// events then accumulate in the outbox.
+//
+// Parameters:
+//   - ctx: Stops the dispatcher when cancelled.
+//   - store: The outbox and delivery store.
+//   - interval: The time between runs.
+//   - logger: Records delivery failures.
+func Start(ctx context.Context, store Store, interval time.Duration, logger zerolog.Logger) {
+	if interval <= 0 {
+		logger.Info().Msg("webhook delivery disabled")
+		return
+	}
+
+	d := newDispatcher(store, logger)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			d.run(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// run fans out the outbox and sends every due delivery.
+func (d *dispatcher) run(ctx context.Context) {
+	for {
+		n, err := d.store.FanOutWebhookEvents(eventBatch)
+		if err != nil {
+			d.logger.Error().Err(err).Msg("webhook event fan-out failed")
+			break
+		}
+		if n < eventBatch {
+			break
+		}
+	}
+
+	for ctx.Err() == nil {
+		due, err := d.store.ClaimWebhookDeliveries(deliveryBatch, lease)
+		if err != nil {
+			d.logger.Error().Err(err).Msg("claiming webhook deliveries failed")
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range due {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.send(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(due) < deliveryBatch {
+			return
+		}
+	}
+}
+
+// send delivers once and records the outcome.
+func (d *dispatcher) send(ctx context.Context, delivery database.DueDelivery) {
+	attempt := d.deliver(ctx, delivery)
+
+	if attempt.Error != "" {
+		d.logger.Warn().
+			Str("delivery_id", delivery.ID.String()).
+			Str("webhook_id", delivery.WebhookID.String()).
+			Str("event", delivery.Event).
+			Int("attempt", delivery.Attempts+1).
+			Int("status", attempt.StatusCode).
+			Str("error", attempt.Error).
+			Bool("retry", !attempt.RetryAt.IsZero()).
+			Msg("webhook delivery failed")
+	}
+
+	if err := d.store.RecordWebhookAttempt(delivery.ID, attempt); err != nil {
+		d.logger.Error().Err(err).Str("delivery_id", delivery.ID.String()).Msg("recording webhook attempt failed")
+	}
+}
+
+// deliver posts the payload to the endpoint. Any 2xx response acknowledges the
+// delivery; failures are retried after Backoff until MaxAttempts is reached.
+// Endpoints resolving to internal addresses fail without retries.
+func (d *dispatcher) deliver(ctx context.Context, delivery database.DueDelivery) database.DeliveryAttempt {
+	var attempt database.DeliveryAttempt
+	now := time.Now()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "template-service-webhooks")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, now, body))
+
+	resp, err := d.client.Do(req)
+	if errors.Is(err, netguard.ErrBlockedAddress) {
+		attempt.Error = "endpoint resolves to a private or internal address"
+		return attempt
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	} else {
+		// Drain a bounded amount so the connection can be reused.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		attempt.StatusCode = resp.StatusCode
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		}
+	}
+
+	if attempt.Error == "" {
+		return attempt
+	}
+
+	if len(attempt.Error) > maxErrorLen {
+		attempt.Error = attempt.Error[:maxErrorLen]
+	}
+	if failed := delivery.Attempts + 1; failed < MaxAttempts {
+		attempt.RetryAt = now.Add(Backoff(failed))
+	}
+
+	return attempt
+}
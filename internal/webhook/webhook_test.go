@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dashboard-platform/template-service/internal/database"
+	"github.com/dashboard-platform/template-service/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSign verifies the signature format and that it covers the timestamp and body.
+func TestSign(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	sig := Sign("secret", at, []byte(`{"a":1}`))
+	require.Equal(t, "t=1700000000,v1=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686", sig)
+
+	require.NotEqual(t, sig, Sign("other", at, []byte(`{"a":1}`)))
+	require.NotEqual(t, sig, Sign("secret", at.Add(time.Second), []byte(`{"a":1}`)))
+	require.NotEqual(t, sig, Sign("secret", at, []byte(`{"a":2}`)))
+}
+
+// TestBackoff verifies that delays double per attempt up to the cap.
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{9, 128 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, maxDelay},
+		{100, maxDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.attempt), func(t *testing.T) {
+			require.Equal(t, tt.want, Backoff(tt.attempt))
+		})
+	}
+}
+
+// memoryStore is an in-memory Store.
+type memoryStore struct {
+	mu       sync.Mutex
+	due      []database.DueDelivery
+	attempts map[uuid.UUID]database.DeliveryAttempt
+}
+
+func (s *memoryStore) FanOutWebhookEvents(int) (int, error) {
+	return 0, nil
+}
+
+func (s *memoryStore) ClaimWebhookDeliveries(limit int, _ time.Duration) ([]database.DueDelivery, error) {
+	n := min(limit, len(s.due))
+	claimed := s.due[:n]
+	s.due = s.due[n:]
+	return claimed, nil
+}
+
+func (s *memoryStore) RecordWebhookAttempt(id uuid.UUID, attempt database.DeliveryAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[id] = attempt
+	return nil
+}
+
+func due(url string, attempts int) database.DueDelivery {
+	return database.DueDelivery{
+		WebhookDelivery: models.WebhookDelivery{
+			ID:       uuid.New(),
+			Event:    database.EventTemplateCreated,
+			Payload:  []byte(`{"event":"template.created"}`),
+			Attempts: attempts,
+		},
+		URL:    url,
+		Secret: "whsec_test",
+	}
+}
+
+// TestRun verifies signed delivery and the retry decisions recorded per outcome.
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		sig := r.Header.Get(SignatureHeader)
+		ts, _, _ := strings.Cut(strings.TrimPrefix(sig, "t="), ",")
+		unix, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil || sig != Sign("whsec_test", time.Unix(unix, 0), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != database.EventTemplateCreated || r.Header.Get(DeliveryHeader) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusNoContent)
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ok := due(server.URL+"/ok", 0)
+	moved := due(server.URL+"/moved", 0)
+	failing := due(server.URL+"/fail", 2)
+	last := due(server.URL+"/fail", MaxAttempts-1)
+	unreachable := due("http://127.0.0.1:0/", 0)
+
+	store := &memoryStore{
+		due:      []database.DueDelivery{ok, moved, failing, last, unreachable},
+		attempts: map[uuid.UUID]database.DeliveryAttempt{},
+	}
+
+	// The test server listens on loopback, which the dispatcher refuses.
+	d := newDispatcher(store, zerolog.Nop())
+	d.client.Transport = http.DefaultTransport
+
+	start := time.Now()
+	d.run(context.Background())
+
+	require.Len(t, store.attempts, 5)
+	require.Equal(t, database.DeliveryAttempt{StatusCode: http.StatusNoContent}, store.attempts[ok.ID])
+
+	require.Equal(t, http.StatusFound, store.attempts[moved.ID].StatusCode)
+	require.NotEmpty(t, store.attempts[moved.ID].Error)
+
+	retry := store.attempts[failing.ID]
+	require.Equal(t, http.StatusInternalServerError, retry.StatusCode)
+	require.Equal(t, "unexpected status 500", retry.Error)
+	require.WithinRange(t, retry.RetryAt, start.Add(Backoff(3)), time.Now().Add(Backoff(3)))
+
+	require.Equal(t, http.StatusInternalServerError, store.attempts[last.ID].StatusCode)
+	require.True(t, store.attempts[last.ID].RetryAt.IsZero())
+
+	require.Zero(t, store.attempts[unreachable.ID].StatusCode)
+	require.NotEmpty(t, store.attempts[unreachable.ID].Error)
+	require.False(t, store.attempts[unreachable.ID].RetryAt.IsZero())
+}
+
+// TestRunBlocksInternalAddresses verifies that deliveries to internal addresses
+// fail at dial time without being retried.
+func TestRunBlocksInternalAddresses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	internal := due(server.URL, 0)
+	store := &memoryStore{
+		due:      []database.DueDelivery{internal},
+		attempts: map[uuid.UUID]database.DeliveryAttempt{},
+	}
+
+	newDispatcher(store, zerolog.Nop()).run(context.Background())
+
+	require.Zero(t, requests)
+	require.Equal(t, database.DeliveryAttempt{Error: "endpoint resolves to a private or internal address"}, store.attempts[internal.ID])
+}
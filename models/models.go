@@ -16,19 +16,30 @@ type Response struct {
 
 type Template struct {
 	gorm.Model
-	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
-	UserID      uuid.UUID `gorm:"not null;index"`
-	Name        string    `gorm:"not null"`
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID  `gorm:"not null;index"`
+	OrgID       *uuid.UUID `gorm:"type:uuid;index"` // nil for personal templates
+	Name        string     `gorm:"not null"`
 	Description string
 	Type        string `gorm:"not null"` // html, latex, etc.
 	Category    string
-	IsPublic    bool `gorm:"default:false"`
+	IsPublic    bool       `gorm:"default:false"`
+	CatalogID   *uuid.UUID `gorm:"type:uuid;index"` // optional message catalog for {{t}}
+	FolderID    *uuid.UUID `gorm:"type:uuid;index"` // nil for the workspace root
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 
+	// Latest version content, indexed for full-text search
+	SearchContent string `gorm:"type:text"`
+
+	// Provenance of cloned templates
+	SourceTemplateID *uuid.UUID `gorm:"type:uuid;index"`
+	SourceVersion    int
+
 	// Relations
 	Versions []TemplateVersion `gorm:"foreignKey:TemplateID"`
 	Fields   []TemplateField   `gorm:"foreignKey:TemplateID"`
+	Tags     []Tag             `gorm:"many2many:template_tags"`
 }
 
 func (t *Template) ToDTO() TemplateDTO {
@@ -52,15 +63,34 @@ func (t *Template) ToDTO() TemplateDTO {
 		}
 	}
 
+	tags := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	var source *TemplateSourceDTO
+	if t.SourceTemplateID != nil {
+		source = &TemplateSourceDTO{
+			TemplateID: t.SourceTemplateID.String(),
+			Version:    t.SourceVersion,
+		}
+	}
+
 	return TemplateDTO{
 		ID:          t.ID.String(),
 		Name:        t.Name,
 		Description: t.Description,
 		Type:        t.Type,
 		Category:    t.Category,
+		OrgID:       t.OrgID,
+		IsPublic:    t.IsPublic,
+		CatalogID:   t.CatalogID,
+		FolderID:    t.FolderID,
+		Tags:        tags,
 		CreatedAt:   t.CreatedAt,
 		Fields:      fields,
 		Version:     latest,
+		Source:      source,
 	}
 }
 
@@ -81,18 +111,81 @@ type TemplateField struct {
 	Label      string         `gorm:"not null"`
 	Type       string         `gorm:"not null;default:text"` // text, number, date, etc.
 	Required   bool           `gorm:"default:true"`
-	Options    datatypes.JSON `gorm:"type:jsonb"` // optional, for select fields
+	Options    datatypes.JSON `gorm:"type:jsonb"` // optional, choices of select fields or item fields of lists
 	CreatedAt  time.Time
 }
 
 type TemplateHistory struct {
 	gorm.Model
 	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
-	UserID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index"` // uuid.Nil for share link renders
 	TemplateID   uuid.UUID `gorm:"type:uuid;not null;index"`
 	Version      int       `gorm:"not null"`
 	TemplateName string    `gorm:"not null"`
 	UsedAt       time.Time `gorm:"autoCreateTime"`
+
+	// Render inputs
+	Values          datatypes.JSON `gorm:"type:jsonb"` // nil when encrypted
+	EncryptedValues []byte         `gorm:"type:bytea"` // AES-GCM sealed values JSON
+	Snapshot        datatypes.JSON `gorm:"type:jsonb"` // RenderSnapshot
+
+	// Render output
+	Format     string // template type the output was produced for
+	OutputHash string // SHA-256 of the output, hex encoded
+	OutputSize int
+	DurationMS int64
+
+	// Client identity
+	ClientType string     // user, api_key, share_link
+	APIKeyID   *uuid.UUID `gorm:"type:uuid"`
+	ClientIP   string
+	UserAgent  string
+}
+
+func (h *TemplateHistory) ToDTO() HistoryDTO {
+	return HistoryDTO{
+		ID:           h.ID.String(),
+		TemplateID:   h.TemplateID.String(),
+		TemplateName: h.TemplateName,
+		Version:      h.Version,
+		Values:       json.RawMessage(h.Values),
+		Encrypted:    h.EncryptedValues != nil,
+		Format:       h.Format,
+		OutputHash:   h.OutputHash,
+		OutputSize:   h.OutputSize,
+		DurationMS:   h.DurationMS,
+		ClientType:   h.ClientType,
+		APIKeyID:     h.APIKeyID,
+		ClientIP:     h.ClientIP,
+		UserAgent:    h.UserAgent,
+		UsedAt:       h.UsedAt,
+	}
+}
+
+type HistoryDTO struct {
+	ID           string          `json:"id"`
+	TemplateID   string          `json:"template_id"`
+	TemplateName string          `json:"template_name"`
+	Version      int             `json:"version"`
+	Values       json.RawMessage `json:"values,omitempty"`
+	Encrypted    bool            `json:"encrypted"`
+	Format       string          `json:"format"`
+	OutputHash   string          `json:"output_hash"`
+	OutputSize   int             `json:"output_size"`
+	DurationMS   int64           `json:"duration_ms"`
+	ClientType   string          `json:"client_type"`
+	APIKeyID     *uuid.UUID      `json:"api_key_id,omitempty"`
+	ClientIP     string          `json:"client_ip"`
+	UserAgent    string          `json:"user_agent"`
+	UsedAt       time.Time       `json:"used_at"`
+}
+
+// RenderSnapshot captures the translation state of a render, so that it can be
+// reproduced after the template's catalog changes.
+type RenderSnapshot struct {
+	Locale        string                       `json:"locale,omitempty"`
+	DefaultLocale string                       `json:"default_locale,omitempty"`
+	Messages      map[string]map[string]string `json:"messages,omitempty"` // nil without a catalog
 }
 
 type TemplateDTO struct {
@@ -101,9 +194,64 @@ type TemplateDTO struct {
 	Description string             `json:"description"`
 	Type        string             `json:"type"`
 	Category    string             `json:"category"`
+	OrgID       *uuid.UUID         `json:"org_id,omitempty"`
+	IsPublic    bool               `json:"is_public"`
+	CatalogID   *uuid.UUID         `json:"catalog_id,omitempty"`
+	FolderID    *uuid.UUID         `json:"folder_id,omitempty"`
+	Tags        []string           `json:"tags"`
 	CreatedAt   time.Time          `json:"created_at"`
 	Fields      []FieldDTO         `json:"fields"`
 	Version     TemplateVersionDTO `json:"version"`
+	Source      *TemplateSourceDTO `json:"source,omitempty"`
+}
+
+// TemplateSummaryDTO is the list representation of a template, without content or fields.
+type TemplateSummaryDTO struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description"`
+	Type          string     `json:"type"`
+	Category      string     `json:"category"`
+	OrgID         *uuid.UUID `json:"org_id,omitempty"`
+	IsPublic      bool       `json:"is_public"`
+	FolderID      *uuid.UUID `json:"folder_id,omitempty"`
+	LatestVersion int        `json:"latest_version"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TemplateSearchResultDTO is a search hit with its relevance and a highlighted
+// excerpt of the matching content. The snippet is HTML-escaped, with matches
+// wrapped in <mark> elements, so it can be inserted into a page as is.
+type TemplateSearchResultDTO struct {
+	TemplateSummaryDTO
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet" doc:"HTML-escaped excerpt of the content; matches are wrapped in <mark> elements, the only markup it contains."`
+}
+
+// TemplateUsageDTO is a template with the caller's usage of it.
+type TemplateUsageDTO struct {
+	TemplateSummaryDTO
+	LastUsedAt time.Time `json:"last_used_at"`
+	UseCount   int64     `json:"use_count"`
+	IsFavorite bool      `json:"is_favorite"`
+}
+
+type TemplateSourceDTO struct {
+	TemplateID string `json:"template_id"`
+	Version    int    `json:"version"`
+}
+
+type UpstreamDTO struct {
+	SourceTemplateID string `json:"source_template_id"`
+	BaseVersion      int    `json:"base_version"`
+	UpstreamVersion  int    `json:"upstream_version"`
+	UpToDate         bool   `json:"up_to_date"`
+	Diff             string `json:"diff"` // unified diff from the fork's head to upstream
+}
+
+type CloneTemplateAPI struct {
+	Name string `json:"name"` // optional, defaults to the source name
 }
 
 type FieldDTO struct {
@@ -125,6 +273,7 @@ type CreateTemplateAPI struct {
 	Type        string             `json:"type" binding:"required"` // html, latex, etc.
 	Category    string             `json:"category"`
 	Content     string             `json:"content" binding:"required"`
+	CatalogID   *uuid.UUID         `json:"catalog_id"` // optional message catalog
 	Fields      []TemplateFieldAPI `json:"fields"`
 }
 
@@ -133,5 +282,564 @@ type TemplateFieldAPI struct {
 	Label    string          `json:"label" binding:"required"`
 	Type     string          `json:"type"` // optional default = "text"
 	Required bool            `json:"required"`
-	Options  json.RawMessage `json:"options"` // optional for select
+	Options  json.RawMessage `json:"options"` // optional choices of select fields or item fields of lists
+}
+
+// RenderAPI is the body of a preview or shared render request.
+type RenderAPI struct {
+	Values map[string]any `json:"values"`
+	Locale string         `json:"locale"` // optional, defaults to Accept-Language
+}
+
+// ImportResultDTO maps the templates and catalogs of an imported bundle to the
+// resources they were imported as.
+type ImportResultDTO struct {
+	Templates []ImportedTemplateDTO `json:"templates"`
+	Catalogs  []ImportedCatalogDTO  `json:"catalogs"`
+}
+
+type ImportedTemplateDTO struct {
+	SourceID string `json:"source_id"` // ID in the exporting environment
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Versions int    `json:"versions"`
+}
+
+type ImportedCatalogDTO struct {
+	SourceID string `json:"source_id"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Created  bool   `json:"created"` // false if a catalog of the same name was reused
+}
+
+type MessageCatalog struct {
+	gorm.Model
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	UserID        uuid.UUID      `gorm:"type:uuid;not null;index"`
+	OrgID         *uuid.UUID     `gorm:"type:uuid;index"` // nil for personal catalogs
+	Name          string         `gorm:"not null"`
+	DefaultLocale string         `gorm:"not null;default:en"`
+	Messages      datatypes.JSON `gorm:"type:jsonb"` // key -> locale -> ICU message
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (c *MessageCatalog) ToDTO() CatalogDTO {
+	messages := map[string]map[string]string{}
+	_ = json.Unmarshal(c.Messages, &messages)
+
+	return CatalogDTO{
+		ID:            c.ID.String(),
+		Name:          c.Name,
+		DefaultLocale: c.DefaultLocale,
+		Messages:      messages,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+	}
+}
+
+type CatalogDTO struct {
+	ID            string                       `json:"id"`
+	Name          string                       `json:"name"`
+	DefaultLocale string                       `json:"default_locale"`
+	Messages      map[string]map[string]string `json:"messages"`
+	CreatedAt     time.Time                    `json:"created_at"`
+	UpdatedAt     time.Time                    `json:"updated_at"`
+}
+
+type CatalogAPI struct {
+	Name          string                       `json:"name" binding:"required"`
+	DefaultLocale string                       `json:"default_locale"` // optional default = "en"
+	Messages      map[string]map[string]string `json:"messages"`
+}
+
+type TemplateShare struct {
+	gorm.Model
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TemplateID uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_template_share_user"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_template_share_user"`
+	Role       string    `gorm:"not null"` // viewer, editor, owner
+	GrantedBy  uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (s *TemplateShare) ToDTO() ShareDTO {
+	return ShareDTO{
+		UserID:    s.UserID.String(),
+		Role:      s.Role,
+		GrantedBy: s.GrantedBy.String(),
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type ShareDTO struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	GrantedBy string    `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ShareAPI struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"required"` // viewer, editor, owner
+}
+
+type Organization struct {
+	gorm.Model
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Name      string    `gorm:"not null"`
+	CreatedBy uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// Relations
+	Members []OrgMember `gorm:"foreignKey:OrgID"`
+}
+
+type OrgMember struct {
+	gorm.Model
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrgID     uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_org_member_user"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_org_member_user"`
+	Role      string    `gorm:"not null"` // owner, admin, editor, viewer
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type OrganizationDTO struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"` // the caller's role
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrgMemberDTO struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *OrgMember) ToDTO() OrgMemberDTO {
+	return OrgMemberDTO{
+		UserID:    m.UserID.String(),
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+type CreateOrganizationAPI struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type OrgMemberAPI struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"required"` // owner, admin, editor, viewer
+}
+
+type APIKey struct {
+	gorm.Model
+	ID          uuid.UUID                      `gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID                      `gorm:"type:uuid;not null;index"` // the user the key acts as
+	OrgID       *uuid.UUID                     `gorm:"type:uuid;index"`          // nil for personal workspace keys
+	Name        string                         `gorm:"not null"`
+	Prefix      string                         `gorm:"not null"`             // leading characters shown to identify the key
+	Hash        string                         `gorm:"not null;uniqueIndex"` // SHA-256 of the key, hex encoded
+	Scopes      datatypes.JSONSlice[string]    `gorm:"type:jsonb"`           // render, read
+	TemplateIDs datatypes.JSONSlice[uuid.UUID] `gorm:"type:jsonb"`           // empty for every template
+	ExpiresAt   *time.Time
+	LastUsedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// HasScope reports whether the key grants the given scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) ToDTO() APIKeyDTO {
+	templateIDs := make([]string, 0, len(k.TemplateIDs))
+	for _, id := range k.TemplateIDs {
+		templateIDs = append(templateIDs, id.String())
+	}
+
+	return APIKeyDTO{
+		ID:          k.ID.String(),
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		Scopes:      k.Scopes,
+		TemplateIDs: templateIDs,
+		ExpiresAt:   k.ExpiresAt,
+		LastUsedAt:  k.LastUsedAt,
+		CreatedAt:   k.CreatedAt,
+	}
+}
+
+type APIKeyDTO struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	TemplateIDs []string   `json:"template_ids"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyAPI struct {
+	Name        string      `json:"name" binding:"required"`
+	Scopes      []string    `json:"scopes" binding:"required"` // render, read
+	TemplateIDs []uuid.UUID `json:"template_ids"`              // optional, empty for every template
+	ExpiresAt   *time.Time  `json:"expires_at"`                // optional, never expires if nil
+}
+
+type CreateShareLinkAPI struct {
+	Version   int        `json:"version"`    // optional, defaults to the latest version
+	Actions   []string   `json:"actions"`    // optional view, render; default = both
+	ExpiresAt *time.Time `json:"expires_at"` // optional, defaults to 7 days from now
+}
+
+type ShareLinkDTO struct {
+	Token      string    `json:"token"`
+	TemplateID string    `json:"template_id"`
+	Version    int       `json:"version"`
+	Actions    []string  `json:"actions"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// SharedTemplateDTO is what a share link reveals about a template: enough to
+// build a render form, but not the template content.
+type SharedTemplateDTO struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Type        string     `json:"type"`
+	Version     int        `json:"version"`
+	Fields      []FieldDTO `json:"fields"`
+}
+
+type Tag struct {
+	gorm.Model
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	OrgID     *uuid.UUID `gorm:"type:uuid;index"` // nil for personal tags
+	Name      string     `gorm:"not null"`        // unique within the workspace
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (t *Tag) ToDTO() TagDTO {
+	return TagDTO{
+		ID:        t.ID.String(),
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+type TagDTO struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TagAPI struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type TemplateTagsAPI struct {
+	Tags []string `json:"tags"` // tag names, created on demand; replaces the current tags
+}
+
+type Folder struct {
+	gorm.Model
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	OrgID     *uuid.UUID `gorm:"type:uuid;index"` // nil for personal folders
+	ParentID  *uuid.UUID `gorm:"type:uuid;index"` // nil for top-level folders
+	Name      string     `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (f *Folder) ToDTO() FolderDTO {
+	return FolderDTO{
+		ID:        f.ID.String(),
+		ParentID:  f.ParentID,
+		Name:      f.Name,
+		CreatedAt: f.CreatedAt,
+		UpdatedAt: f.UpdatedAt,
+	}
+}
+
+type FolderDTO struct {
+	ID        string     `json:"id"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type FolderAPI struct {
+	Name     string     `json:"name" binding:"required"`
+	ParentID *uuid.UUID `json:"parent_id"` // optional, nil for a top-level folder
+}
+
+type MoveTemplateAPI struct {
+	FolderID *uuid.UUID `json:"folder_id"` // nil moves the template to the workspace root
+}
+
+type Favorite struct {
+	gorm.Model
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_favorite_user_template"`
+	TemplateID uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_favorite_user_template"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type StatsBucketDTO struct {
+	Start   time.Time `json:"start"`
+	Renders int64     `json:"renders"`
+	Users   int64     `json:"users"`
+}
+
+type VersionStatsDTO struct {
+	Version    int       `json:"version"`
+	Renders    int64     `json:"renders"`
+	Users      int64     `json:"users"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+type TopTemplateDTO struct {
+	TemplateID uuid.UUID `json:"template_id"`
+	Name       string    `json:"name"`
+	Renders    int64     `json:"renders"`
+	Users      int64     `json:"users"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// StatsDTO aggregates renders over a time range. Users counts distinct signed-in
+// users and API key owners; anonymous share link renders only count as renders.
+type StatsDTO struct {
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	Bucket        string            `json:"bucket"`
+	TotalRenders  int64             `json:"total_renders"`
+	DistinctUsers int64             `json:"distinct_users"`
+	Buckets       []StatsBucketDTO  `json:"buckets"`
+	Versions      []VersionStatsDTO `json:"versions,omitempty"`      // per-template stats only
+	TopTemplates  []TopTemplateDTO  `json:"top_templates,omitempty"` // workspace stats only
+}
+
+type RetentionPolicy struct {
+	gorm.Model
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	OrgID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex"`
+	MaxAgeDays int       // 0 keeps entries regardless of age
+	KeepLast   int       // 0 keeps every entry per template
+	UpdatedBy  uuid.UUID `gorm:"type:uuid;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type RetentionPolicyDTO struct {
+	MaxAgeDays int    `json:"max_age_days"`
+	KeepLast   int    `json:"keep_last"`
+	Source     string `json:"source"` // org or global
+}
+
+type RetentionPolicyAPI struct {
+	MaxAgeDays int `json:"max_age_days"` // 0 keeps entries regardless of age
+	KeepLast   int `json:"keep_last"`    // 0 keeps every entry per template
+}
+
+type PurgeCandidateDTO struct {
+	TemplateID   uuid.UUID `json:"template_id"`
+	TemplateName string    `json:"template_name"`
+	Entries      int64     `json:"entries"`
+	Oldest       time.Time `json:"oldest"`
+	Newest       time.Time `json:"newest"`
+}
+
+// PurgeReportDTO describes what a purge would remove from a workspace.
+type PurgeReportDTO struct {
+	Policy    RetentionPolicyDTO  `json:"policy"`
+	Total     int64               `json:"total"`
+	Templates []PurgeCandidateDTO `json:"templates"`
+}
+
+// Problem is the RFC 7807 problem document returned for every failed request.
+// The error member carries the machine-readable code for clients that do not
+// read problem documents.
+type Problem struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status"`
+	Detail   string   `json:"detail"`
+	Instance string   `json:"instance,omitempty"`
+	Error    ErrorDTO `json:"error"`
+}
+
+type ErrorDTO struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+}
+
+// IdempotencyKey records the outcome of a request sent with an Idempotency-Key
+// header. Status is 0 while the first request is still running.
+type IdempotencyKey struct {
+	gorm.Model
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Owner       string    `gorm:"not null;uniqueIndex:idx_idempotency_owner_key"` // user ID or share link path
+	Key         string    `gorm:"not null;uniqueIndex:idx_idempotency_owner_key"`
+	RequestHash string    `gorm:"not null"`
+	Status      int
+	ContentType string
+	Body        []byte    `gorm:"type:bytea"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Webhook is an endpoint notified of template events in its workspace.
+type Webhook struct {
+	gorm.Model
+	ID        uuid.UUID                   `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID                   `gorm:"type:uuid;not null;index"` // creator; the workspace for personal webhooks
+	OrgID     *uuid.UUID                  `gorm:"type:uuid;index"`          // nil for personal workspace webhooks
+	URL       string                      `gorm:"not null"`
+	Secret    string                      `gorm:"not null"`   // signs deliveries; empty when encrypted
+	Events    datatypes.JSONSlice[string] `gorm:"type:jsonb"` // empty for every event
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	EncryptedSecret []byte `gorm:"type:bytea"` // AES-GCM sealed Secret
+}
+
+// Subscribes reports whether the webhook is notified of the given event.
+func (w *Webhook) Subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) ToDTO() WebhookDTO {
+	events := []string(w.Events)
+	if events == nil {
+		events = []string{}
+	}
+
+	return WebhookDTO{
+		ID:        w.ID.String(),
+		URL:       w.URL,
+		Events:    events,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+type WebhookDTO struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookAPI struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"` // optional, empty for every event
+}
+
+// WebhookEvent is an event in the outbox. It is written in the transaction that
+// changes the template, and fanned out into deliveries by the dispatcher.
+type WebhookEvent struct {
+	gorm.Model
+	ID        uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	Event     string         `gorm:"not null"`
+	UserID    uuid.UUID      `gorm:"type:uuid;not null"`  // template owner, for personal workspaces
+	OrgID     *uuid.UUID     `gorm:"type:uuid"`           // template organization
+	Payload   datatypes.JSON `gorm:"type:jsonb;not null"` // WebhookPayloadDTO
+	CreatedAt time.Time      `gorm:"index"`
+}
+
+// WebhookDelivery is an event sent, or to be sent, to one webhook.
+type WebhookDelivery struct {
+	gorm.Model
+	ID            uuid.UUID      `gorm:"type:uuid;primaryKey"`
+	WebhookID     uuid.UUID      `gorm:"type:uuid;not null;index"`
+	EventID       uuid.UUID      `gorm:"type:uuid;not null"`
+	Event         string         `gorm:"not null"`
+	Payload       datatypes.JSON `gorm:"type:jsonb;not null"`
+	Status        string         `gorm:"not null;index"` // pending, succeeded, failed
+	Attempts      int            `gorm:"not null"`
+	NextAttemptAt time.Time      `gorm:"index"`
+	LastStatus    int            // HTTP status of the last attempt; 0 without a response
+	LastError     string
+	DeliveredAt   *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (d *WebhookDelivery) ToDTO() WebhookDeliveryDTO {
+	dto := WebhookDeliveryDTO{
+		ID:          d.ID.String(),
+		EventID:     d.EventID.String(),
+		Event:       d.Event,
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		LastStatus:  d.LastStatus,
+		LastError:   d.LastError,
+		Payload:     json.RawMessage(d.Payload),
+		DeliveredAt: d.DeliveredAt,
+		CreatedAt:   d.CreatedAt,
+	}
+	if d.Status == "pending" {
+		dto.NextAttemptAt = &d.NextAttemptAt
+	}
+	return dto
+}
+
+type WebhookDeliveryDTO struct {
+	ID            string          `json:"id"`
+	EventID       string          `json:"event_id"`
+	Event         string          `json:"event"`
+	Status        string          `json:"status"` // pending, succeeded, failed
+	Attempts      int             `json:"attempts"`
+	LastStatus    int             `json:"last_status,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"` // pending deliveries only
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// WebhookPayloadDTO is the body of a webhook delivery.
+type WebhookPayloadDTO struct {
+	ID        string              `json:"id"` // event ID, the same for every webhook
+	Event     string              `json:"event"`
+	CreatedAt time.Time           `json:"created_at"`
+	Data      WebhookEventDataDTO `json:"data"`
+}
+
+type WebhookEventDataDTO struct {
+	TemplateID   string     `json:"template_id"`
+	TemplateName string     `json:"template_name"`
+	OrgID        *uuid.UUID `json:"org_id,omitempty"`
+	ActorID      *uuid.UUID `json:"actor_id,omitempty"`   // the user who caused the event, if any
+	Version      int        `json:"version,omitempty"`    // published or rendered version
+	HistoryID    string     `json:"history_id,omitempty"` // template.rendered only
 }